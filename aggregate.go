@@ -0,0 +1,193 @@
+package crudiator
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// The SQL aggregate function applied by an Aggregation
+type AggregateFunc string
+
+const (
+	CountAggregate AggregateFunc = "COUNT"
+	SumAggregate   AggregateFunc = "SUM"
+	AvgAggregate   AggregateFunc = "AVG"
+	MinAggregate   AggregateFunc = "MIN"
+	MaxAggregate   AggregateFunc = "MAX"
+)
+
+// An aggregate column computed by 'Aggregate()' over a read, non redacted field, keyed by Alias
+// which defaults to the function and field names. i.e. 'sum_age', or 'count' for COUNT(*)
+type Aggregation struct {
+	Func  AggregateFunc
+	Field string
+	Alias string
+}
+
+// Returns a copy of the aggregation with the given result alias
+func (a Aggregation) As(alias string) Aggregation {
+	a.Alias = alias
+	return a
+}
+
+func (a Aggregation) alias() string {
+	if a.Alias != "" {
+		return a.Alias
+	}
+	if a.Field == "" {
+		return strings.ToLower(string(a.Func))
+	}
+	return strings.ToLower(string(a.Func)) + "_" + a.Field
+}
+
+// COUNT(*)
+func CountAll() Aggregation {
+	return Aggregation{Func: CountAggregate}
+}
+
+// COUNT(field), which only counts non null values
+func CountOf(field string) Aggregation {
+	return Aggregation{Func: CountAggregate, Field: field}
+}
+
+func Sum(field string) Aggregation {
+	return Aggregation{Func: SumAggregate, Field: field}
+}
+
+func Avg(field string) Aggregation {
+	return Aggregation{Func: AvgAggregate, Field: field}
+}
+
+func Min(field string) Aggregation {
+	return Aggregation{Func: MinAggregate, Field: field}
+}
+
+func Max(field string) Aggregation {
+	return Aggregation{Func: MaxAggregate, Field: field}
+}
+
+// Returns whether the values of the field may be returned, i.e. the field is read, for the
+// role of the editor, and not redacted
+func (e Editor) hasField(name string) bool {
+	for _, f := range e.fields {
+		if f.Name == name {
+			return f.Read && !f.Redacted
+		}
+	}
+	return false
+}
+
+func (e Editor) quote(name string) string {
+	return string(e.quoteRune) + name + string(e.quoteRune)
+}
+
+func (e Editor) aggregateStatement(groupBy []string, aggregations []Aggregation) (statement, error) {
+	var builder strings.Builder
+	var separator bool
+
+	if len(aggregations) == 0 {
+		return statement{}, errors.Errorf("at least one aggregation is required")
+	}
+
+	builder.WriteString("SELECT ")
+	for _, g := range groupBy {
+		if !e.hasField(g) {
+			return statement{}, errors.Errorf("unknown group field '%s'", g)
+		}
+		if separator {
+			builder.WriteRune(',')
+		}
+		builder.WriteString(e.quote(g))
+		separator = true
+	}
+
+	for _, a := range aggregations {
+		switch a.Func {
+		case CountAggregate, SumAggregate, AvgAggregate, MinAggregate, MaxAggregate:
+		default:
+			return statement{}, errors.Errorf("unsupported aggregate function '%s'", a.Func)
+		}
+		if separator {
+			builder.WriteRune(',')
+		}
+		builder.WriteString(string(a.Func))
+		builder.WriteRune('(')
+		if a.Field == "" {
+			if a.Func != CountAggregate {
+				return statement{}, errors.Errorf("%s requires a field", a.Func)
+			}
+			builder.WriteRune('*')
+		} else {
+			if !e.hasField(a.Field) {
+				return statement{}, errors.Errorf("unknown aggregate field '%s'", a.Field)
+			}
+			builder.WriteString(e.quote(a.Field))
+		}
+		builder.WriteString(") AS ")
+		builder.WriteString(e.quote(a.alias()))
+		separator = true
+	}
+
+	builder.WriteString(" FROM ")
+	builder.WriteString(e.tableNameQuoted)
+
+	if len(e.filterFields) > 0 {
+		builder.WriteString(" WHERE (")
+		builder.WriteString(ParameterizeFields(e.filterFields, e.dialect, true))
+		builder.WriteRune(')')
+	}
+	s := statement{at: builder.Len(), where: len(e.filterFields) > 0, bound: e.filterFieldCount}
+
+	if len(groupBy) > 0 {
+		quoted := make([]string, len(groupBy))
+		for i, g := range groupBy {
+			quoted[i] = e.quote(g)
+		}
+		builder.WriteString(" GROUP BY ")
+		builder.WriteString(strings.Join(quoted, ","))
+		builder.WriteString(" ORDER BY ")
+		builder.WriteString(strings.Join(quoted, ","))
+	}
+
+	s.sql = builder.String()
+	return s, nil
+}
+
+// Aggregate computes the aggregations over the rows matching the selection filters, grouped by
+// the given fields. The pre-read callback is invoked before the query is executed.
+func (e Editor) Aggregate(form DataForm, db *sql.DB, groupBy []string, aggregations ...Aggregation) ([]DbRow, error) {
+	return e.AggregateContext(context.Background(), form, db, groupBy, aggregations...)
+}
+
+func (e Editor) AggregateContext(ctx context.Context, form DataForm, db *sql.DB, groupBy []string, aggregations ...Aggregation) ([]DbRow, error) {
+	e = e.forRole(ctx)
+	if err := e.checkOperation(OpRead); err != nil {
+		return nil, err
+	}
+	s, err := e.aggregateStatement(groupBy, aggregations)
+	if err != nil {
+		return nil, err
+	}
+
+	e.invokePreActionCallback(e.preRead, form)
+	predicates, err := e.authorize(ctx, OpRead, form)
+	if err != nil {
+		return nil, err
+	}
+	fieldValues, err := e.getFieldValues(ctx, e.filterFields, form)
+	if err != nil {
+		return nil, err
+	}
+	query, fieldValues := s.with(e.dialect, fieldValues, predicates)
+	e.logger.Debug("aggregate statement => %s", query)
+
+	rows, err := db.QueryContext(ctx, query, fieldValues...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return e.scanRows(rows)
+}
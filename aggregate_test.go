@@ -0,0 +1,64 @@
+package crudiator_test
+
+import (
+	"database/sql/driver"
+	"testing"
+
+	"github.com/SharkFourSix/crudiator"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCount(t *testing.T) {
+	db, fdb := newFakeDb(t)
+	fdb.queueRows([]string{"count"}, []driver.Value{int64(7)})
+
+	count, err := newStudentEditor(crudiator.MYSQL, deletedAtFilter, schoolFilter).Build().Count(crudiator.MapBackedDataForm{"school_id": 3}, db)
+	require.NoError(t, err)
+	require.Equal(t, int64(7), count)
+	require.Equal(t, "SELECT COUNT(*) FROM `students` WHERE (`deleted_at` IS NULL AND `school_id`=?)", fdb.last().Query)
+	require.Equal(t, []any{3}, fdb.last().Args)
+}
+
+func TestExists(t *testing.T) {
+	db, fdb := newFakeDb(t)
+	fdb.queueRows([]string{"exists"}, []driver.Value{int64(1)})
+
+	exists, err := newStudentEditor(crudiator.SQLITE, deletedAtFilter, schoolFilter).Build().Exists(crudiator.MapBackedDataForm{"school_id": 3}, db)
+	require.NoError(t, err)
+	require.True(t, exists)
+	require.Equal(t, "SELECT EXISTS(SELECT 1 FROM `students` WHERE (`deleted_at` IS NULL AND `school_id`=?))", fdb.last().Query)
+}
+
+func TestAggregate(t *testing.T) {
+	db, fdb := newFakeDb(t)
+	fdb.queueRows([]string{"school_id", "sum_age", "students"},
+		[]driver.Value{int64(1), int64(40), int64(2)},
+		[]driver.Value{int64(2), int64(18), int64(1)},
+	)
+
+	editor := newStudentEditor(
+		crudiator.POSTGRESQL,
+		crudiator.NewField("age", crudiator.IncludeAlways),
+		deletedAtFilter,
+		schoolFilter,
+		crudiator.NewField("notes", crudiator.IncludeOnCreate),
+		crudiator.NewField("password_hash", crudiator.IncludeOnCreate, crudiator.RedactOnRead),
+	).Build()
+	rows, err := editor.Aggregate(crudiator.MapBackedDataForm{"school_id": 3}, db,
+		[]string{"school_id"}, crudiator.Sum("age"), crudiator.CountAll().As("students"))
+	require.NoError(t, err)
+	require.Equal(t, `SELECT "school_id",SUM("age") AS "sum_age",COUNT(*) AS "students" FROM "students" WHERE ("deleted_at" IS NULL AND "school_id"=$1) GROUP BY "school_id" ORDER BY "school_id"`, fdb.last().Query)
+	require.Len(t, rows, 2)
+	require.Equal(t, int64(40), rows[0]["sum_age"])
+
+	_, err = editor.Aggregate(crudiator.MapBackedDataForm{}, db, nil, crudiator.Max("salary"))
+	require.Error(t, err)
+
+	// fields that are not read, or redacted, are never aggregated
+	queries := len(fdb.all())
+	_, err = editor.Aggregate(crudiator.MapBackedDataForm{}, db, nil, crudiator.Max("password_hash"))
+	require.ErrorContains(t, err, "unknown aggregate field 'password_hash'")
+	_, err = editor.Aggregate(crudiator.MapBackedDataForm{}, db, []string{"notes"}, crudiator.CountAll())
+	require.ErrorContains(t, err, "unknown group field 'notes'")
+	require.Len(t, fdb.all(), queries)
+}
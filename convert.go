@@ -0,0 +1,417 @@
+package crudiator
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+// Layouts tried, in order, when converting text to time.Time
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999-07",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02",
+}
+
+func conversionError(v any, target string) error {
+	return errors.Errorf("cannot convert %T (%v) to %s", v, v, target)
+}
+
+func asString(v any) (string, error) {
+	switch t := v.(type) {
+	case string:
+		return t, nil
+	case []byte:
+		return string(t), nil
+	case json.RawMessage:
+		return string(t), nil
+	case time.Time:
+		return t.Format(time.RFC3339Nano), nil
+	case fmt.Stringer:
+		return t.String(), nil
+	case bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return fmt.Sprint(t), nil
+	}
+	return "", conversionError(v, "string")
+}
+
+func asInt64(v any) (int64, error) {
+	switch t := v.(type) {
+	case int64:
+		return t, nil
+	case bool:
+		if t {
+			return 1, nil
+		}
+		return 0, nil
+	case json.Number:
+		return asInt64(string(t))
+	case []byte:
+		return asInt64(string(t))
+	case string:
+		s := strings.TrimSpace(t)
+		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return i, nil
+		}
+		// accept integral decimals such as "42.0"
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return asInt64(f)
+		}
+		return 0, conversionError(v, "int64")
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if rv.Uint() > math.MaxInt64 {
+			return 0, conversionError(v, "int64")
+		}
+		return int64(rv.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		f := rv.Float()
+		// float64(math.MaxInt64) is 2^63, which overflows int64
+		if f != math.Trunc(f) || f >= 9223372036854775808.0 || f < math.MinInt64 {
+			return 0, conversionError(v, "int64")
+		}
+		return int64(f), nil
+	}
+	return 0, conversionError(v, "int64")
+}
+
+func asFloat64(v any) (float64, error) {
+	switch t := v.(type) {
+	case float64:
+		return t, nil
+	case json.Number:
+		return asFloat64(string(t))
+	case []byte:
+		return asFloat64(string(t))
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(t), 64)
+		if err != nil {
+			return 0, conversionError(v, "float64")
+		}
+		return f, nil
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), nil
+	}
+	return 0, conversionError(v, "float64")
+}
+
+func asBool(v any) (bool, error) {
+	switch t := v.(type) {
+	case bool:
+		return t, nil
+	case []byte:
+		return asBool(string(t))
+	case string:
+		s := strings.ToLower(strings.TrimSpace(t))
+		switch s {
+		case "1", "t", "true", "y", "yes", "on":
+			return true, nil
+		case "0", "f", "false", "n", "no", "off", "":
+			return false, nil
+		}
+		return false, conversionError(v, "bool")
+	}
+	if i, err := asInt64(v); err == nil {
+		switch i {
+		case 0:
+			return false, nil
+		case 1:
+			return true, nil
+		}
+	}
+	return false, conversionError(v, "bool")
+}
+
+func asTime(v any) (time.Time, error) {
+	switch t := v.(type) {
+	case time.Time:
+		return t, nil
+	case *time.Time:
+		if t != nil {
+			return *t, nil
+		}
+	case []byte:
+		return asTime(string(t))
+	case string:
+		s := strings.TrimSpace(t)
+		for _, layout := range timeLayouts {
+			if parsed, err := time.Parse(layout, s); err == nil {
+				return parsed, nil
+			}
+		}
+	case int64:
+		// unix timestamps, commonly stored in SQLite
+		return time.Unix(t, 0), nil
+	}
+	return time.Time{}, conversionError(v, "time.Time")
+}
+
+func asBytes(v any) ([]byte, error) {
+	switch t := v.(type) {
+	case []byte:
+		return t, nil
+	case json.RawMessage:
+		return []byte(t), nil
+	case string:
+		return []byte(t), nil
+	}
+	return nil, conversionError(v, "[]byte")
+}
+
+func asRawJson(v any) (json.RawMessage, error) {
+	switch t := v.(type) {
+	case json.RawMessage:
+		return t, nil
+	case []byte:
+		return json.RawMessage(t), nil
+	case string:
+		return json.RawMessage(t), nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, conversionError(v, "json")
+	}
+	return json.RawMessage(data), nil
+}
+
+// NormalizeValue converts a value read from the database into the Go type of the field type.
+// nil values and values of UnknownField are returned as is.
+func NormalizeValue(v any, t FieldType) (any, error) {
+	if v == nil {
+		return nil, nil
+	}
+	switch t {
+	case DateField:
+		return asTime(v)
+	case UUIDField:
+		u, err := asUUID(v)
+		if err != nil {
+			return nil, err
+		}
+		return u.String(), nil
+	case DecimalField:
+		return asDecimalString(v)
+	case StringField:
+		return asString(v)
+	case IntField:
+		return asInt64(v)
+	case FloatField:
+		return asFloat64(v)
+	case BoolField:
+		return asBool(v)
+	case TimestampField:
+		return asTime(v)
+	case JsonField:
+		return asRawJson(v)
+	case BytesField:
+		return asBytes(v)
+	}
+	return v, nil
+}
+
+// Maps a database type name, as reported by sql.ColumnType.DatabaseTypeName(), to a field type
+func fieldTypeOf(databaseType string) FieldType {
+	name := strings.ToUpper(strings.TrimSpace(databaseType))
+	if i := strings.IndexRune(name, '('); i >= 0 {
+		name = strings.TrimSpace(name[:i])
+	}
+	name = strings.TrimPrefix(name, "UNSIGNED ")
+	name = strings.TrimSuffix(name, " UNSIGNED")
+
+	switch name {
+	case "":
+		return UnknownField
+	case "INT", "INTEGER", "INT2", "INT4", "INT8", "TINYINT", "SMALLINT", "MEDIUMINT", "BIGINT",
+		"SERIAL", "BIGSERIAL", "SMALLSERIAL", "YEAR":
+		return IntField
+	case "REAL", "FLOAT", "FLOAT4", "FLOAT8", "DOUBLE", "DOUBLE PRECISION":
+		return FloatField
+	case "NUMERIC", "DECIMAL":
+		return DecimalField
+	case "BOOL", "BOOLEAN":
+		return BoolField
+	case "DATE", "DATETIME", "TIMESTAMP", "TIMESTAMPTZ", "TIMESTAMP WITH TIME ZONE", "TIMESTAMP WITHOUT TIME ZONE":
+		return TimestampField
+	case "JSON", "JSONB":
+		return JsonField
+	case "BLOB", "TINYBLOB", "MEDIUMBLOB", "LONGBLOB", "BYTEA", "BINARY", "VARBINARY":
+		return BytesField
+	case "CHAR", "VARCHAR", "NCHAR", "NVARCHAR", "CHARACTER", "CHARACTER VARYING", "BPCHAR", "TEXT", "TINYTEXT",
+		"MEDIUMTEXT", "LONGTEXT", "CLOB", "NAME", "CITEXT", "UUID", "ENUM", "SET", "TIME", "TIMETZ", "INTERVAL",
+		"MONEY":
+		return StringField
+	}
+	return UnknownField
+}
+
+func asDecimal(v any) (*big.Rat, error) {
+	switch t := v.(type) {
+	case *big.Rat:
+		return new(big.Rat).Set(t), nil
+	case json.Number:
+		return asDecimal(string(t))
+	case []byte:
+		return asDecimal(string(t))
+	case string:
+		r, ok := new(big.Rat).SetString(strings.TrimSpace(t))
+		if !ok {
+			return nil, conversionError(v, "decimal")
+		}
+		return r, nil
+	case float32, float64:
+		f, _ := asFloat64(t)
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return nil, conversionError(v, "decimal")
+		}
+		// go through the shortest decimal representation so that 0.1 stays 0.1
+		return asDecimal(strconv.FormatFloat(f, 'f', -1, 64))
+	}
+	if i, err := asInt64(v); err == nil {
+		return new(big.Rat).SetInt64(i), nil
+	}
+	return nil, conversionError(v, "decimal")
+}
+
+func asUUID(v any) (uuid.UUID, error) {
+	switch t := v.(type) {
+	case uuid.UUID:
+		return t, nil
+	case [16]byte:
+		return uuid.UUID(t), nil
+	case []byte:
+		if len(t) == 16 {
+			return uuid.FromBytes(t)
+		}
+		return asUUID(string(t))
+	case string:
+		u, err := uuid.Parse(strings.TrimSpace(t))
+		if err != nil {
+			return uuid.Nil, conversionError(v, "uuid")
+		}
+		return u, nil
+	}
+	return uuid.Nil, conversionError(v, "uuid")
+}
+
+// Returns the exact decimal representation of v, suitable for binding to DECIMAL/NUMERIC columns
+func asDecimalString(v any) (string, error) {
+	switch t := v.(type) {
+	case json.Number:
+		return asDecimalString(string(t))
+	case []byte:
+		return asDecimalString(string(t))
+	case string:
+		s := strings.TrimSpace(t)
+		if _, ok := new(big.Rat).SetString(s); !ok || strings.ContainsRune(s, '/') {
+			return "", conversionError(v, "decimal")
+		}
+		return s, nil
+	case *big.Rat:
+		if t.IsInt() {
+			return t.Num().String(), nil
+		}
+		// rationals parsed from decimals have a denominator of the form 2^n * 5^m, so n+m digits suffice
+		digits := t.Denom().BitLen()
+		return strings.TrimRight(t.FloatString(digits), "0"), nil
+	}
+	r, err := asDecimal(v)
+	if err != nil {
+		return "", err
+	}
+	return asDecimalString(r)
+}
+
+func asJsonText(v any) (string, error) {
+	switch t := v.(type) {
+	case json.RawMessage, []byte, string:
+		data, _ := asBytes(t)
+		if !json.Valid(data) {
+			return "", conversionError(v, "json")
+		}
+		return string(data), nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", conversionError(v, "json")
+	}
+	return string(data), nil
+}
+
+// CoerceValue converts a form value into the Go type bound for the field type. Empty strings are
+// converted to nil for all types but StringField and BytesField.
+func CoerceValue(v any, t FieldType) (any, error) {
+	if v == nil || t == UnknownField {
+		return v, nil
+	}
+	if s, ok := v.(string); ok && strings.TrimSpace(s) == "" && t != StringField && t != BytesField {
+		return nil, nil
+	}
+	switch t {
+	case StringField:
+		return asString(v)
+	case IntField:
+		return asInt64(v)
+	case FloatField:
+		return asFloat64(v)
+	case BoolField:
+		return asBool(v)
+	case TimestampField:
+		return asTime(v)
+	case DateField:
+		d, err := asTime(v)
+		if err != nil {
+			return nil, err
+		}
+		return d.Format("2006-01-02"), nil
+	case UUIDField:
+		return asUUID(v)
+	case JsonField:
+		return asJsonText(v)
+	case BytesField:
+		return asBytes(v)
+	case DecimalField:
+		return asDecimalString(v)
+	}
+	return v, nil
+}
+
+// CoercionError is returned by Create, Read, Update and Delete when a form value cannot be
+// coerced into the declared type of its field.
+type CoercionError struct {
+	Field string
+	Type  FieldType
+	Value any
+	Err   error
+}
+
+func (e *CoercionError) Error() string {
+	return fmt.Sprintf("field '%s': invalid %s value: %s", e.Field, e.Type, e.Err)
+}
+
+func (e *CoercionError) Unwrap() error {
+	return e.Err
+}
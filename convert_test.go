@@ -0,0 +1,171 @@
+package crudiator_test
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/SharkFourSix/crudiator"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeValue(t *testing.T) {
+	cases := []struct {
+		value    any
+		t        crudiator.FieldType
+		expected any
+	}{
+		{[]byte("John"), crudiator.StringField, "John"},
+		{[]byte("42"), crudiator.IntField, int64(42)},
+		{"42.0", crudiator.IntField, int64(42)},
+		{[]byte("1.5"), crudiator.FloatField, 1.5},
+		{int64(2), crudiator.FloatField, 2.0},
+		{int64(1), crudiator.BoolField, true},
+		{[]byte("f"), crudiator.BoolField, false},
+		{[]byte("2024-01-02 03:04:05"), crudiator.TimestampField, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{"2024-01-02", crudiator.TimestampField, time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{[]byte(`{"a":1}`), crudiator.JsonField, json.RawMessage(`{"a":1}`)},
+		{"raw", crudiator.BytesField, []byte("raw")},
+		{[]byte("as is"), crudiator.UnknownField, []byte("as is")},
+		{nil, crudiator.IntField, nil},
+	}
+	for _, c := range cases {
+		actual, err := crudiator.NormalizeValue(c.value, c.t)
+		require.NoError(t, err)
+		require.Equal(t, c.expected, actual)
+	}
+
+	_, err := crudiator.NormalizeValue([]byte("abc"), crudiator.IntField)
+	require.Error(t, err)
+}
+
+func TestFieldTypeValues(t *testing.T) {
+	require.Equal(t, crudiator.FieldType(0), crudiator.IntField)
+	require.Equal(t, crudiator.FieldType(1), crudiator.BoolField)
+	require.Equal(t, crudiator.FieldType(2), crudiator.TimestampField)
+	require.False(t, crudiator.NewField("name").TypeDeclared)
+	require.True(t, crudiator.NewField("id", crudiator.OfType(crudiator.IntField)).TypeDeclared)
+
+	// fields built as struct literals are not declared as int
+	db, fdb := newFakeDb(t)
+	editor := crudiator.MustNewEditor(
+		"students",
+		crudiator.POSTGRESQL,
+		crudiator.Field{Name: "id", PrimaryKey: true, Read: true},
+		crudiator.Field{Name: "name", Create: true, Read: true},
+	).Build()
+	fdb.queueRows([]string{"id", "name"}, []driver.Value{int64(1), "Jane"})
+	_, err := editor.Create(crudiator.MapBackedDataForm{"name": "Jane"}, db)
+	require.NoError(t, err)
+	require.Equal(t, []any{"Jane"}, fdb.last().Args)
+}
+
+func TestEditorNormalizeValues(t *testing.T) {
+	db, fdb := newFakeDb(t)
+	fdb.columnDb["name"] = "VARCHAR"
+	fdb.columnDb["score"] = "DECIMAL"
+	fdb.columnDb["meta"] = "JSON"
+	fdb.columnDb["fee"] = "MONEY"
+	fdb.queueRows([]string{"id", "name", "score", "meta", "active", "fee"},
+		[]driver.Value{[]byte("7"), []byte("Jane"), []byte("9.5"), []byte(`{"grade":"A"}`), int64(1), []byte("$1,250.00")},
+	)
+
+	editor := crudiator.MustNewEditor(
+		"students",
+		crudiator.MYSQL,
+		crudiator.NewField("id", crudiator.IsPrimaryKey, crudiator.IncludeOnRead, crudiator.OfType(crudiator.IntField)),
+		crudiator.NewField("name", crudiator.IncludeOnRead),
+		crudiator.NewField("score", crudiator.IncludeOnRead),
+		crudiator.NewField("meta", crudiator.IncludeOnRead),
+		crudiator.NewField("active", crudiator.IncludeOnRead, crudiator.OfType(crudiator.BoolField)),
+		crudiator.NewField("fee", crudiator.IncludeOnRead),
+	).NormalizeValues(true).Build()
+
+	rows, err := editor.Read(crudiator.MapBackedDataForm{}, db)
+	require.NoError(t, err)
+	require.Equal(t, crudiator.DbRow{
+		"id":     int64(7),
+		"name":   "Jane",
+		"score":  "9.5",
+		"meta":   json.RawMessage(`{"grade":"A"}`),
+		"active": true,
+		"fee":    "$1,250.00",
+	}, rows[0])
+
+	data, err := json.Marshal(rows[0])
+	require.NoError(t, err)
+	require.JSONEq(t, `{"id":7,"name":"Jane","score":"9.5","meta":{"grade":"A"},"active":true,"fee":"$1,250.00"}`, string(data))
+}
+
+func TestCoerceValue(t *testing.T) {
+	id := uuid.MustParse("7d444840-9dc0-11d1-b245-5ffdce74fad2")
+	cases := []struct {
+		value    any
+		t        crudiator.FieldType
+		expected any
+	}{
+		{"42", crudiator.IntField, int64(42)},
+		{"", crudiator.IntField, nil},
+		{"true", crudiator.BoolField, true},
+		{"2.5", crudiator.FloatField, 2.5},
+		{"2024-01-01T10:00:00Z", crudiator.TimestampField, time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)},
+		{"2024-01-01", crudiator.DateField, "2024-01-01"},
+		{id.String(), crudiator.UUIDField, id},
+		{`{"grade":"A"}`, crudiator.JsonField, `{"grade":"A"}`},
+		{map[string]any{"grade": "A"}, crudiator.JsonField, `{"grade":"A"}`},
+		{"abc", crudiator.BytesField, []byte("abc")},
+		{"19.990", crudiator.DecimalField, "19.990"},
+		{0.1, crudiator.DecimalField, "0.1"},
+		{int64(7), crudiator.StringField, "7"},
+		{"", crudiator.StringField, ""},
+	}
+	for _, c := range cases {
+		actual, err := crudiator.CoerceValue(c.value, c.t)
+		require.NoError(t, err, "%v as %s", c.value, c.t)
+		require.Equal(t, c.expected, actual, "%v as %s", c.value, c.t)
+	}
+
+	for _, c := range []struct {
+		value any
+		t     crudiator.FieldType
+	}{
+		{"abc", crudiator.IntField},
+		{"maybe", crudiator.BoolField},
+		{"yesterday", crudiator.DateField},
+		{"not-a-uuid", crudiator.UUIDField},
+		{"{broken", crudiator.JsonField},
+		{"1/3", crudiator.DecimalField},
+	} {
+		_, err := crudiator.CoerceValue(c.value, c.t)
+		require.Error(t, err, "%v as %s", c.value, c.t)
+	}
+}
+
+func TestEditorCoercesFormValues(t *testing.T) {
+	db, fdb := newFakeDb(t)
+	editor := crudiator.MustNewEditor(
+		"students",
+		crudiator.POSTGRESQL,
+		crudiator.NewField("id", crudiator.IsPrimaryKey, crudiator.IncludeOnRead, crudiator.OfType(crudiator.IntField)),
+		crudiator.NewField("name", crudiator.IncludeAlways),
+		crudiator.NewField("active", crudiator.IncludeAlways, crudiator.OfType(crudiator.BoolField)),
+		crudiator.NewField("enrolled_on", crudiator.IncludeAlways, crudiator.OfType(crudiator.DateField)),
+		crudiator.NewField("school_id", crudiator.IncludeOnRead, crudiator.IsSelectionFilter, crudiator.OfType(crudiator.IntField)),
+	).Build()
+
+	fdb.queueRows([]string{"id"}, []driver.Value{int64(1)})
+	form := crudiator.MapBackedDataForm{"id": "1", "name": "Jane", "active": "on", "enrolled_on": "2024-01-01", "school_id": "3"}
+	_, err := editor.Update(form, db)
+	require.NoError(t, err)
+	require.Equal(t, []any{"Jane", true, "2024-01-01", int64(1), int64(3)}, fdb.last().Args)
+
+	form["active"] = "maybe"
+	_, err = editor.Update(form, db)
+	var coercionErr *crudiator.CoercionError
+	require.ErrorAs(t, err, &coercionErr)
+	require.Equal(t, "active", coercionErr.Field)
+	require.Equal(t, crudiator.BoolField, coercionErr.Type)
+	require.Len(t, fdb.all(), 1, "no statement must be executed")
+}
@@ -1,10 +1,12 @@
 package crudiator
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
@@ -19,19 +21,93 @@ const (
 	SQLITE
 )
 
+// Crudiator performs CRUD operations on a table. The operations without a context use
+// context.Background().
 type Crudiator interface {
 	Create(form DataForm, db *sql.DB) (DbRow, error)
+	CreateContext(ctx context.Context, form DataForm, db *sql.DB) (DbRow, error)
+
+	// Creates the row along with the child rows nested in the form, in a single transaction
+	CreateNested(form DataForm, db *sql.DB) (DbRow, error)
+	CreateNestedContext(ctx context.Context, form DataForm, db *sql.DB) (DbRow, error)
+
 	Read(form DataForm, db *sql.DB, pageable ...Pageable) ([]DbRow, error)
+	ReadContext(ctx context.Context, form DataForm, db *sql.DB, pageable ...Pageable) ([]DbRow, error)
+
+	// Reads a single page of rows along with pagination metadata.
+	//
+	// The page is fetched with one extra row in order to determine whether there are more rows to
+	// read. If withTotal is true, a COUNT query using the same selection filters is also executed.
+	ReadPage(form DataForm, db *sql.DB, pageable Pageable, withTotal bool) (*Page, error)
+	ReadPageContext(ctx context.Context, form DataForm, db *sql.DB, pageable Pageable, withTotal bool) (*Page, error)
+
+	// Reads rows lazily through an iterator instead of buffering the entire result set.
+	//
+	// The caller must close the iterator once done with it.
+	ReadIter(form DataForm, db *sql.DB, pageable ...Pageable) (*RowIterator, error)
+	ReadIterContext(ctx context.Context, form DataForm, db *sql.DB, pageable ...Pageable) (*RowIterator, error)
 
 	// Reads a single database row. May return nil,nil if no row exists
 	SingleRead(form DataForm, db *sql.DB) (DbRow, error)
+	SingleReadContext(ctx context.Context, form DataForm, db *sql.DB) (DbRow, error)
+
+	// Reads a single database row and locks it until the transaction ends. May return nil,nil
+	// if no row exists
+	SingleReadForUpdate(form DataForm, tx *sql.Tx, lock LockMode) (DbRow, error)
+	SingleReadForUpdateContext(ctx context.Context, form DataForm, tx *sql.Tx, lock LockMode) (DbRow, error)
+
+	// Reads rows and locks them until the transaction ends
+	ReadForUpdate(form DataForm, tx *sql.Tx, lock LockMode, pageable ...Pageable) ([]DbRow, error)
+	ReadForUpdateContext(ctx context.Context, form DataForm, tx *sql.Tx, lock LockMode, pageable ...Pageable) ([]DbRow, error)
 	// Updates the specified record and returns the updated row.
 	//
 	// A single statement is executed for postgres (using the RETURNING keyword) and for
 	// all others, two statements are executed; one to update and one for the query.
 	Update(form DataForm, db *sql.DB) (DbRow, error)
+	UpdateContext(ctx context.Context, form DataForm, db *sql.DB) (DbRow, error)
 
 	Delete(form DataForm, db *sql.DB) (DbRow, error)
+	DeleteContext(ctx context.Context, form DataForm, db *sql.DB) (DbRow, error)
+
+	// Restores a soft deleted row along with the rows soft deleted with it
+	Restore(form DataForm, db *sql.DB) (DbRow, error)
+	RestoreContext(ctx context.Context, form DataForm, db *sql.DB) (DbRow, error)
+
+	// Returns the number of rows matching the selection filters
+	Count(form DataForm, db *sql.DB) (int64, error)
+	CountContext(ctx context.Context, form DataForm, db *sql.DB) (int64, error)
+
+	// Returns whether any row matches the selection filters
+	Exists(form DataForm, db *sql.DB) (bool, error)
+	ExistsContext(ctx context.Context, form DataForm, db *sql.DB) (bool, error)
+
+	// Computes the given aggregations over the rows matching the selection filters, optionally
+	// grouped by the given fields. Each returned row is keyed by the group fields and the
+	// aggregation aliases.
+	Aggregate(form DataForm, db *sql.DB, groupBy []string, aggregations ...Aggregation) ([]DbRow, error)
+	AggregateContext(ctx context.Context, form DataForm, db *sql.DB, groupBy []string, aggregations ...Aggregation) ([]DbRow, error)
+
+	// Reads the ancestors of a row of a hierarchy, from its parent to the root
+	Ancestors(form DataForm, db *sql.DB) ([]DbRow, error)
+	AncestorsContext(ctx context.Context, form DataForm, db *sql.DB) ([]DbRow, error)
+
+	// Reads the descendants of a row of a hierarchy down to the given depth, 0 meaning all
+	Descendants(form DataForm, db *sql.DB, depth int) ([]DbRow, error)
+	DescendantsContext(ctx context.Context, form DataForm, db *sql.DB, depth int) ([]DbRow, error)
+
+	// Reads a row of a hierarchy along with all its descendants
+	Subtree(form DataForm, db *sql.DB) ([]DbRow, error)
+	SubtreeContext(ctx context.Context, form DataForm, db *sql.DB) ([]DbRow, error)
+
+	// Removes the keys of the form which are not accepted for the operation
+	SanitizeForm(op Operation, form DataForm) DataForm
+
+	// Returns whether the operation is enabled on the editor
+	Allows(op Operation) bool
+
+	// Re-encrypts the values of encrypted fields that were not encrypted with the current key of
+	// their keyring, walking the table in batches. Returns the number of updated rows
+	ReEncrypt(ctx context.Context, db *sql.DB, batchSize int) (int64, error)
 }
 
 type PreActionCallback func(editor Editor, form DataForm)
@@ -58,18 +134,47 @@ type Editor struct {
 	fieldList                string
 	tableName                string
 	tableNameQuoted          string
+	query                    string // SELECT statement of query editors
 	pagination               PaginationStrategy
 	keysetPaginationField    string
+	streamChunkSize          int
+	normalize                bool
+	declaredTypes            map[string]FieldType
+	strict                   bool
+	fieldsByName             map[string]Field
+	versionField             string
+	tenantField              string
+	tenant                   func(ctx context.Context) any
+	policies                 []Policy
+	disabledOperations       map[Operation]bool
+	relations                []relation
+	parentField              string // column referencing the parent row, see Hierarchy()
+	role                     func(ctx context.Context) string
+	roleEditors              *sync.Map       // role => *Editor, nil for editors without role restrictions
+	declaredRoles            map[string]bool // roles named by the role options of the fields
+	roleBound                bool            // whether the editor is the editor of a role
+	readDecoders             map[string]valueDecoder
+	redacted                 map[string]bool
+	clock                    func() time.Time
+	utc                      bool
+	sqlTimestamps            bool
+	acceptedFormKeys         map[Operation]map[string]bool
 	createStatement          string
-	singleSelectionStatement string
-	readStatement            string
-	updateStatement          string
-	deleteStatement          string
+	singleSelectionStatement statement
+	readStatement            statement
+	updateStatement          statement
+	deleteStatement          statement
+	restoreStatement         statement
+	countStatement           statement
+	existsStatement          statement
 	createFields             []string
+	createParams             []string // create fields bound to parameters
 	readFields               []string
 	updateFields             []string
+	updateParams             []string // update fields bound to parameters
 	filterFields             []string
-	filterFieldCount         int // actual parameterized fields
+	filterNames              map[string]string // filter expressions => field names
+	filterFieldCount         int               // actual parameterized fields
 	primaryKeyField          string
 	logger                   Logger
 	dbg                      bool
@@ -197,6 +302,53 @@ func (e *Editor) OnPostDelete(f PostActionCallback) *Editor {
 	return e
 }
 
+// StreamChunkSize sets the number of rows a RowIterator scans ahead and passes to the post-read
+// callback at a time. Defaults to DefaultStreamChunkSize.
+func (e *Editor) StreamChunkSize(n int) *Editor {
+	e.streamChunkSize = n
+	return e
+}
+
+// NormalizeValues toggles the conversion of scanned values with 'NormalizeValue()', using the
+// type declared through 'OfType()' or the database type reported by the driver.
+func (e *Editor) NormalizeValues(b bool) *Editor {
+	e.normalize = b
+	return e
+}
+
+// SetClock sets the function returning the current time, used for automatic and soft deletion
+// timestamps. Defaults to time.Now
+func (e *Editor) SetClock(clock func() time.Time) *Editor {
+	e.clock = clock
+	return e
+}
+
+// UTC toggles the conversion of automatic and soft deletion timestamps to UTC
+func (e *Editor) UTC(b bool) *Editor {
+	e.utc = b
+	return e
+}
+
+// SQLTimestamps toggles whether automatic timestamps are computed by the database, by writing
+// CURRENT_TIMESTAMP in the generated statements, instead of being bound from the editor's clock.
+func (e *Editor) SQLTimestamps(b bool) *Editor {
+	e.sqlTimestamps = b
+	return e
+}
+
+func (e Editor) now() time.Time {
+	var t time.Time
+	if e.clock != nil {
+		t = e.clock()
+	} else {
+		t = time.Now()
+	}
+	if e.utc {
+		t = t.UTC()
+	}
+	return t
+}
+
 // SoftDelete Indicates whether records in this table should be soft deleted.
 //
 // If true, a call to 'Delete' is converted to an 'Update', with only
@@ -223,9 +375,13 @@ func (e *Editor) MustPaginate(strategy PaginationStrategy, fields ...string) *Ed
 func (e *Editor) buildFilterFields() *Editor {
 	count := 0
 	e.filterFields = make([]string, 0)
+	e.filterNames = make(map[string]string)
 	for _, f := range e.fields {
 		if f.SelectionFilter {
-			fieldSpec := fmt.Sprintf("%c%s%c", e.quoteRune, f.Name, e.quoteRune)
+			fieldSpec := e.fieldExpression(f, false)
+			if f.JSONColumn != "" {
+				e.filterNames[fieldSpec] = f.Name
+			}
 			if f.NullCheck == FieldMustBeNull {
 				fieldSpec += " IS NULL"
 			} else if f.NullCheck == FieldMustNotBeNull {
@@ -243,7 +399,7 @@ func (e *Editor) buildFilterFields() *Editor {
 func (e *Editor) buildCreateFields() *Editor {
 	e.createFields = make([]string, 0)
 	for _, f := range e.fields {
-		if f.Create {
+		if f.Create || f.managedOn(OpCreate) {
 			e.createFields = append(e.createFields, fmt.Sprintf("%c%s%c", e.quoteRune, f.Name, e.quoteRune))
 		}
 	}
@@ -263,7 +419,8 @@ func (e *Editor) buildReadFields() *Editor {
 func (e *Editor) buildUpdateFields() *Editor {
 	e.updateFields = make([]string, 0)
 	for _, f := range e.fields {
-		if f.Update {
+		// the version is incremented by the statement itself
+		if (f.Update || f.managedOn(OpUpdate)) && !f.Version {
 			e.updateFields = append(e.updateFields, fmt.Sprintf("%c%s%c", e.quoteRune, f.Name, e.quoteRune))
 		}
 	}
@@ -302,6 +459,11 @@ func (e *Editor) Build() Crudiator {
 		e.quoteRune = '"'
 	}
 
+	e.fieldsByName = make(map[string]Field)
+	for _, f := range e.fields {
+		e.fieldsByName[f.Name] = f
+	}
+
 	e.buildCreateFields().
 		buildReadFields().
 		buildUpdateFields().
@@ -314,6 +476,29 @@ func (e *Editor) Build() Crudiator {
 			break
 		}
 	}
+	for _, f := range e.fields {
+		if f.Version {
+			e.versionField = f.Name
+			break
+		}
+	}
+
+	e.buildAcceptedFormKeys()
+	e.buildRoleEditors()
+	e.checkEncryptedFields()
+	e.checkQuerySource()
+	e.checkHierarchy()
+	e.checkJSONPaths()
+	e.buildReadDecoders()
+	e.createParams = e.boundFields(OpCreate, e.createFields)
+	e.updateParams = e.boundFields(OpUpdate, e.updateFields)
+
+	e.declaredTypes = make(map[string]FieldType)
+	for _, f := range e.fields {
+		if f.TypeDeclared {
+			e.declaredTypes[f.Name] = f.Type
+		}
+	}
 
 	// create
 	builder.WriteString("INSERT INTO ")
@@ -322,7 +507,18 @@ func (e *Editor) Build() Crudiator {
 	builder.WriteString(strings.Join(e.createFields, ","))
 	builder.WriteRune(')')
 	builder.WriteString(" VALUES (")
-	builder.WriteString(CreateParameterPlaceholders(len(e.createFields), e.dialect))
+	parameterCount = 0
+	for i, f := range e.createFields {
+		if i > 0 {
+			builder.WriteRune(',')
+		}
+		if expr, ok := e.valueExpression(OpCreate, f); ok {
+			builder.WriteString(expr)
+		} else {
+			parameterCount++
+			builder.WriteString(placeholder(e.dialect, parameterCount))
+		}
+	}
 	builder.WriteRune(')')
 
 	if e.dialect == POSTGRESQL {
@@ -357,7 +553,7 @@ func (e *Editor) Build() Crudiator {
 		builder.WriteRune(')')
 	}
 
-	e.singleSelectionStatement = builder.String()
+	e.singleSelectionStatement = statement{sql: builder.String(), at: builder.Len(), where: true, bound: 1 + e.filterFieldCount}
 
 	// Bulk selection
 	parameterCount = 0
@@ -381,12 +577,14 @@ func (e *Editor) Build() Crudiator {
 	}
 
 	// pagination
+	e.readStatement = statement{at: builder.Len(), where: hasFilters, bound: e.filterFieldCount}
 	if e.pagination == OFFSET {
 		switch e.dialect {
 		case SQLITE:
 			fallthrough
 		case MYSQL:
-			builder.WriteString(" LIMIT ? OFFSET ?")
+			// offset first, as bound
+			builder.WriteString(" LIMIT ?,?")
 		case POSTGRESQL:
 			builder.WriteString(fmt.Sprintf(" OFFSET $%d FETCH NEXT $%d ROWS ONLY", parameterCount+1, parameterCount+2))
 			parameterCount += 2
@@ -404,8 +602,10 @@ func (e *Editor) Build() Crudiator {
 		if e.dialect == POSTGRESQL {
 			builder.WriteRune('$')
 			builder.WriteString(strconv.Itoa(parameterCount + 1))
+			builder.WriteRune(')')
+			e.readStatement = statement{at: builder.Len(), where: true, bound: e.filterFieldCount + 1}
 
-			builder.WriteString(") ORDER BY ")
+			builder.WriteString(" ORDER BY ")
 			builder.WriteRune(e.quoteRune)
 			builder.WriteString(e.keysetPaginationField)
 			builder.WriteRune(e.quoteRune)
@@ -414,7 +614,10 @@ func (e *Editor) Build() Crudiator {
 			builder.WriteRune('$')
 			builder.WriteString(strconv.Itoa(parameterCount + 2))
 		} else {
-			builder.WriteString("?) ORDER BY ")
+			builder.WriteString("?)")
+			e.readStatement = statement{at: builder.Len(), where: true, bound: e.filterFieldCount + 1}
+
+			builder.WriteString(" ORDER BY ")
 			builder.WriteRune(e.quoteRune)
 			builder.WriteString(e.keysetPaginationField)
 			builder.WriteRune(e.quoteRune)
@@ -422,14 +625,55 @@ func (e *Editor) Build() Crudiator {
 		}
 	}
 
-	e.readStatement = builder.String()
+	e.readStatement.sql = builder.String()
+
+	// count and existence statements, sharing the selection filters of the bulk selection
+	builder.Reset()
+	builder.WriteString(" FROM ")
+	builder.WriteString(e.tableNameQuoted)
+	if len(e.filterFields) > 0 {
+		builder.WriteString(" WHERE (")
+		builder.WriteString(ParameterizeFields(e.filterFields, e.dialect, true))
+		builder.WriteRune(')')
+	}
+
+	e.countStatement = statement{
+		sql:   "SELECT COUNT(*)" + builder.String(),
+		at:    len("SELECT COUNT(*)") + builder.Len(),
+		where: len(e.filterFields) > 0,
+		bound: e.filterFieldCount,
+	}
+	e.existsStatement = statement{
+		sql:   "SELECT EXISTS(SELECT 1" + builder.String() + ")",
+		at:    len("SELECT EXISTS(SELECT 1") + builder.Len(),
+		where: len(e.filterFields) > 0,
+		bound: e.filterFieldCount,
+	}
 	builder.Reset()
 	parameterCount = 0
 
 	builder.WriteString("UPDATE ")
 	builder.WriteString(e.tableNameQuoted)
 	builder.WriteString(" SET ")
-	builder.WriteString(ParameterizeFields(e.updateFields, e.dialect, false))
+	for i, f := range e.updateFields {
+		if i > 0 {
+			builder.WriteRune(',')
+		}
+		builder.WriteString(f)
+		builder.WriteRune('=')
+		if expr, ok := e.valueExpression(OpUpdate, f); ok {
+			builder.WriteString(expr)
+		} else {
+			parameterCount++
+			builder.WriteString(placeholder(e.dialect, parameterCount))
+		}
+	}
+	if e.versionField != "" {
+		if len(e.updateFields) > 0 {
+			builder.WriteRune(',')
+		}
+		builder.WriteString(e.versionIncrement())
+	}
 	builder.WriteString(" WHERE ")
 	builder.WriteRune(e.quoteRune)
 	builder.WriteString(e.primaryKeyField)
@@ -437,7 +681,7 @@ func (e *Editor) Build() Crudiator {
 	builder.WriteRune('=')
 
 	if e.dialect == POSTGRESQL {
-		parameterCount = 1 + len(e.updateFields)
+		parameterCount = 1 + len(e.updateParams)
 		builder.WriteRune('$')
 		builder.WriteString(strconv.Itoa(parameterCount))
 		parameterCount++
@@ -452,12 +696,24 @@ func (e *Editor) Build() Crudiator {
 		builder.WriteRune(')')
 	}
 
+	if e.versionField != "" {
+		builder.WriteString(" AND ")
+		builder.WriteString(e.quote(e.versionField))
+		builder.WriteRune('=')
+		builder.WriteString(placeholder(e.dialect, len(e.updateParams)+e.filterFieldCount+2))
+	}
+
+	e.updateStatement = statement{at: builder.Len(), where: true, bound: len(e.updateParams) + e.filterFieldCount + 1}
+	if e.versionField != "" {
+		e.updateStatement.bound++
+	}
+
 	if e.dialect == POSTGRESQL {
 		builder.WriteString(" RETURNING ")
 		builder.WriteString(strings.Join(e.readFields, ","))
 	}
 
-	e.updateStatement = builder.String()
+	e.updateStatement.sql = builder.String()
 
 	builder.Reset()
 	parameterCount = 0
@@ -468,28 +724,24 @@ func (e *Editor) Build() Crudiator {
 		builder.WriteString(e.tableNameQuoted)
 		builder.WriteString(" SET ")
 		builder.WriteString(ParameterizeFields(e.softDeleteColumns, e.dialect, false))
+		if e.versionField != "" {
+			builder.WriteRune(',')
+			builder.WriteString(e.versionIncrement())
+		}
+		parameterCount = len(e.softDeleteColumns)
 	} else {
 		builder.WriteString("DELETE FROM ")
 		builder.WriteString(e.tableNameQuoted)
 	}
 
-	if e.dialect == POSTGRESQL {
-		parameterCount = len(e.softDeleteColumns)
-	}
-
 	builder.WriteString(" WHERE ")
 	builder.WriteRune(e.quoteRune)
 	builder.WriteString(e.primaryKeyField)
 	builder.WriteRune(e.quoteRune)
 	builder.WriteRune('=')
 
-	if e.dialect == POSTGRESQL {
-		builder.WriteRune('$')
-		builder.WriteString(strconv.Itoa(parameterCount + 1))
-		parameterCount++
-	} else {
-		builder.WriteRune('?')
-	}
+	parameterCount++
+	builder.WriteString(placeholder(e.dialect, parameterCount))
 
 	if len(e.filterFields) > 0 {
 		builder.WriteString(" AND ")
@@ -498,18 +750,33 @@ func (e *Editor) Build() Crudiator {
 		builder.WriteRune(')')
 	}
 
+	if e.versionField != "" {
+		builder.WriteString(" AND ")
+		builder.WriteString(e.quote(e.versionField))
+		builder.WriteRune('=')
+		builder.WriteString(placeholder(e.dialect, parameterCount+e.filterFieldCount+1))
+	}
+
+	e.deleteStatement = statement{at: builder.Len(), where: true, bound: parameterCount + e.filterFieldCount}
+	if e.versionField != "" {
+		e.deleteStatement.bound++
+	}
+
 	if e.dialect == POSTGRESQL {
 		builder.WriteString(" RETURNING ")
 		builder.WriteString(strings.Join(e.readFields, ","))
 	}
 
-	e.deleteStatement = builder.String()
+	e.deleteStatement.sql = builder.String()
+	e.buildRestoreStatement()
 
 	e.logger.Debug("create statement => %s", e.createStatement)
-	e.logger.Debug("read statement => %s", e.readStatement)
-	e.logger.Debug("update statement => %s", e.updateStatement)
-	e.logger.Debug("delete statement => %s", e.deleteStatement)
-	e.logger.Debug("single selection statement => %s", e.singleSelectionStatement)
+	e.logger.Debug("read statement => %s", e.readStatement.sql)
+	e.logger.Debug("update statement => %s", e.updateStatement.sql)
+	e.logger.Debug("delete statement => %s", e.deleteStatement.sql)
+	e.logger.Debug("single selection statement => %s", e.singleSelectionStatement.sql)
+	e.logger.Debug("count statement => %s", e.countStatement.sql)
+	e.logger.Debug("exists statement => %s", e.existsStatement.sql)
 
 	return e
 }
@@ -518,33 +785,127 @@ func (e Editor) fieldHasNullConstraint(field string) bool {
 	return strings.HasSuffix(field, "IS NULL") || strings.HasSuffix(field, "IS NOT NULL")
 }
 
-// Returns values in order of field occurrence
-func (e Editor) getFieldValues(fields []string, form DataForm) []any {
+// Returns values in order of field occurrence, coerced into the declared type of each field
+func (e Editor) getFieldValues(ctx context.Context, fields []string, form DataForm) ([]any, error) {
 	var data []any = make([]any, 0)
 	for _, f := range fields {
 		if !e.fieldHasNullConstraint(f) {
 			isquoted := f[0] == byte(e.quoteRune) && f[len(f)-1] == byte(e.quoteRune)
-			if isquoted {
+			if name, ok := e.filterNames[f]; ok {
+				f = name
+			} else if isquoted {
 				f = strings.Trim(f, string(e.quoteRune))
 			}
-			data = append(data, form.Get(f))
+			if f == e.tenantField {
+				value, err := e.tenantValue(ctx)
+				if err != nil {
+					return nil, err
+				}
+				data = append(data, value)
+				continue
+			}
+			value, err := e.bindValue(f, form.Get(f))
+			if err != nil {
+				return nil, err
+			}
+			data = append(data, value)
 		}
 	}
-	return data
+	return data, nil
+}
+
+// Returns the SQL expression written in place of a bound parameter for the field by the
+// operation, if any
+func (e Editor) valueExpression(op Operation, field string) (string, bool) {
+	f := e.fieldsByName[e.unquote(field)]
+	if f.Version {
+		// only written on create, updates increment it
+		return "1", true
+	}
+	if e.sqlTimestamps && f.autoTimestampOn(op) {
+		return "CURRENT_TIMESTAMP", true
+	}
+	return "", false
 }
 
-func (e Editor) getSoftDeletionValues(form DataForm) []any {
+// Returns the fields of the list which are bound to parameters by the operation
+func (e Editor) boundFields(op Operation, fields []string) []string {
+	bound := make([]string, 0, len(fields))
+	for _, f := range fields {
+		if _, ok := e.valueExpression(op, f); !ok {
+			bound = append(bound, f)
+		}
+	}
+	return bound
+}
+
+// Returns the values bound by the create or update statement. Managed fields get their values
+// from their provider or the clock rather than from the form.
+func (e Editor) getWriteValues(ctx context.Context, op Operation, form DataForm) ([]any, error) {
+	fields := e.createParams
+	if op == OpUpdate {
+		fields = e.updateParams
+	}
+	data := make([]any, 0, len(fields))
+	for _, f := range fields {
+		f = e.unquote(f)
+		field := e.fieldsByName[f]
+
+		var value any
+		provider := field.CreateValueProvider
+		if op == OpUpdate {
+			provider = field.UpdateValueProvider
+		}
+		switch {
+		case provider != nil:
+			v, err := provider(ctx, form)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to provide value of '%s'", f)
+			}
+			value = v
+		case field.autoTimestampOn(op):
+			value = e.now()
+		default:
+			value = form.Get(f)
+		}
+
+		value, err := e.bindValue(f, value)
+		if err != nil {
+			return nil, err
+		}
+		if value, err = e.encrypt(f, value); err != nil {
+			return nil, err
+		}
+		data = append(data, value)
+	}
+	return data, nil
+}
+
+func (e Editor) coerceFieldValue(field string, value any) (any, error) {
+	t, ok := e.declaredTypes[field]
+	if !ok {
+		return value, nil
+	}
+	coerced, err := CoerceValue(value, t)
+	if err != nil {
+		return nil, &CoercionError{Field: field, Type: t, Value: value, Err: err}
+	}
+	return coerced, nil
+}
+
+// Returns the values of the soft deletion fields of a row soft deleted at the given time
+func (e Editor) getSoftDeletionValues(at time.Time) []any {
 	var data []any = make([]any, 0)
 	for _, f := range e.fields {
 		if f.SoftDelete {
 			var value any
 			switch f.SoftDeleteType {
-			case IntField:
+			case UnknownField, IntField:
 				value = 1
 			case BoolField:
 				value = true
 			case TimestampField:
-				value = time.Now()
+				value = at
 			}
 			data = append(data, value)
 		}
@@ -552,22 +913,58 @@ func (e Editor) getSoftDeletionValues(form DataForm) []any {
 	return data
 }
 
-func (e Editor) getFieldvalue(field string, form DataForm) any {
-	return form.Get(e.unquote(field))
+func (e Editor) getFieldvalue(field string, form DataForm) (any, error) {
+	field = e.unquote(field)
+	return e.bindValue(field, form.Get(field))
+}
+
+// Returns the values of the single selection statement: the primary key followed by the filters
+func (e Editor) getSingleSelectionValues(ctx context.Context, form DataForm) ([]any, error) {
+	pkv, err := e.getFieldvalue(e.primaryKeyField, form)
+	if err != nil {
+		return nil, err
+	}
+	filterValues, err := e.getFieldValues(ctx, e.filterFields, form)
+	if err != nil {
+		return nil, err
+	}
+	return append([]any{pkv}, filterValues...), nil
 }
 
 func (e Editor) scanRows(rows *sql.Rows) ([]DbRow, error) {
 	var rowset []DbRow = make([]DbRow, 0)
+	scanner, err := e.newRowScanner(rows)
+	if err != nil {
+		return nil, err
+	}
 	for rows.Next() {
-		row := DbRow{}
-		if err := row.Scan(rows); err != nil {
+		row, err := scanner.Scan(rows)
+		if err != nil {
 			return nil, err
 		}
 		rowset = append(rowset, row)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
 	return rowset, nil
 }
 
+func (e Editor) newRowScanner(rows *sql.Rows) (*RowScanner, error) {
+	var scanner *RowScanner
+	var err error
+	if e.normalize {
+		scanner, err = NewNormalizingRowScanner(rows, e.declaredTypes)
+	} else {
+		scanner, err = NewRowScanner(rows)
+	}
+	if err != nil {
+		return nil, err
+	}
+	scanner.decode(e.readDecoders, e.redacted)
+	return scanner, nil
+}
+
 func (e Editor) scanRow(rows *sql.Rows) (DbRow, error) {
 	var row DbRow
 	scanned, err := e.scanRows(rows)
@@ -592,15 +989,51 @@ func (e Editor) unquote(name string) string {
 }
 
 func (e Editor) SingleRead(form DataForm, db *sql.DB) (DbRow, error) {
+	return e.SingleReadContext(context.Background(), form, db)
+}
+
+func (e Editor) SingleReadContext(ctx context.Context, form DataForm, db *sql.DB) (DbRow, error) {
+	e = e.forRole(ctx)
+	if err := e.checkOperation(OpRead); err != nil {
+		return nil, err
+	}
+	predicates, err := e.authorize(ctx, OpRead, form)
+	if err != nil {
+		return nil, err
+	}
+	row, err := e.singleRead(ctx, form, db, predicates)
+	if err != nil || row == nil {
+		return row, err
+	}
+	if err := e.loadIncluded(ctx, db, []DbRow{row}); err != nil {
+		return nil, err
+	}
+	return row, nil
+}
+
+func (e Editor) singleRead(ctx context.Context, form DataForm, q queryer, predicates []Predicate) (DbRow, error) {
+	return e.singleReadWith(ctx, q, e.singleSelectionStatement, form, predicates)
+}
+
+// Executes the given single selection statement
+func (e Editor) singleReadWith(ctx context.Context, q queryer, s statement, form DataForm, predicates []Predicate) (DbRow, error) {
 	var row DbRow
-	args := e.getFieldValues(e.readFields, form)
-	rows, err := db.Query(e.singleSelectionStatement, args...)
+	args, err := e.getSingleSelectionValues(ctx, form)
+	if err != nil {
+		return nil, err
+	}
+	query, args := s.with(e.dialect, args, predicates)
+	rows, err := q.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 	if rows.Next() {
-		err := row.Scan(rows)
+		scanner, err := e.newRowScanner(rows)
+		if err != nil {
+			return nil, err
+		}
+		row, err = scanner.Scan(rows)
 		if err != nil {
 			return nil, err
 		}
@@ -609,16 +1042,47 @@ func (e Editor) SingleRead(form DataForm, db *sql.DB) (DbRow, error) {
 }
 
 func (e Editor) Create(form DataForm, db *sql.DB) (DbRow, error) {
+	return e.CreateContext(context.Background(), form, db)
+}
+
+func (e Editor) CreateContext(ctx context.Context, form DataForm, db *sql.DB) (DbRow, error) {
+	e = e.forRole(ctx)
+	if err := e.checkOperation(OpCreate); err != nil {
+		return nil, err
+	}
+	row, err := e.create(ctx, form, db)
+	if err != nil {
+		return nil, err
+	}
+	e.invokePostActionCallback(e.postCreate, []DbRow{row})
+	return row, nil
+}
+
+// Creates the row, executing the statements with q. The post-create callback is left to the
+// caller.
+func (e Editor) create(ctx context.Context, form DataForm, q queryer) (DbRow, error) {
 	var row DbRow
+	if err := e.checkFormKeys(OpCreate, form); err != nil {
+		return nil, err
+	}
 	e.invokePreActionCallback(e.preCreate, form)
-	fieldValues := e.getFieldValues(e.createFields, form)
+	if _, err := e.authorize(ctx, OpCreate, form); err != nil {
+		return nil, err
+	}
+	if err := e.validate(OpCreate, form); err != nil {
+		return nil, err
+	}
+	fieldValues, err := e.getWriteValues(ctx, OpCreate, form)
+	if err != nil {
+		return nil, err
+	}
 
 	//var query string
 	switch e.dialect {
 	case SQLITE:
 		fallthrough
 	case MYSQL:
-		res, err := db.Exec(e.createStatement, fieldValues...)
+		res, err := q.ExecContext(ctx, e.createStatement, fieldValues...)
 		if err != nil {
 			return nil, err
 		}
@@ -627,13 +1091,13 @@ func (e Editor) Create(form DataForm, db *sql.DB) (DbRow, error) {
 			return nil, err
 		}
 		form.Set(e.unquote(e.primaryKeyField), identifier)
-		dbRow, err := e.SingleRead(form, db)
+		dbRow, err := e.singleRead(ctx, form, q, nil)
 		if err != nil {
 			return nil, err
 		}
 		row = dbRow
 	case POSTGRESQL:
-		rows, err := db.Query(e.createStatement, fieldValues...)
+		rows, err := q.QueryContext(ctx, e.createStatement, fieldValues...)
 		if err != nil {
 			return nil, err
 		}
@@ -643,14 +1107,164 @@ func (e Editor) Create(form DataForm, db *sql.DB) (DbRow, error) {
 			return nil, err
 		}
 	}
-	e.invokePostActionCallback(e.postCreate, []DbRow{row})
 	return row, nil
 }
 
 func (e Editor) Read(form DataForm, db *sql.DB, pageable ...Pageable) ([]DbRow, error) {
-	var results []DbRow
+	return e.ReadContext(context.Background(), form, db, pageable...)
+}
+
+func (e Editor) ReadContext(ctx context.Context, form DataForm, db *sql.DB, pageable ...Pageable) ([]DbRow, error) {
+	e = e.forRole(ctx)
+	if err := e.checkOperation(OpRead); err != nil {
+		return nil, err
+	}
+	e.invokePreActionCallback(e.preRead, form)
+	predicates, err := e.authorize(ctx, OpRead, form)
+	if err != nil {
+		return nil, err
+	}
+	results, err := e.read(ctx, form, db, predicates, pageable...)
+	if err != nil {
+		return nil, err
+	}
+	if err := e.loadIncluded(ctx, db, results); err != nil {
+		return nil, err
+	}
+	e.invokePostActionCallback(e.postRead, results)
+	return results, nil
+}
+
+func (e Editor) ReadPage(form DataForm, db *sql.DB, pageable Pageable, withTotal bool) (*Page, error) {
+	return e.ReadPageContext(context.Background(), form, db, pageable, withTotal)
+}
+
+func (e Editor) ReadPageContext(ctx context.Context, form DataForm, db *sql.DB, pageable Pageable, withTotal bool) (*Page, error) {
+	e = e.forRole(ctx)
+	if err := e.checkOperation(OpRead); err != nil {
+		return nil, err
+	}
+	if e.pagination == NONE {
+		return nil, ErrPaginationNotConfigured
+	}
+	if pageable == nil {
+		return nil, ErrNoPageable
+	}
+	if pageable.Size() <= 0 {
+		return nil, ErrInvalidPageSize
+	}
+	if e.UsesKeysetPagination() && !e.hasField(e.keysetPaginationField) {
+		return nil, ErrKeysetFieldNotRead
+	}
+
+	e.invokePreActionCallback(e.preRead, form)
+	predicates, err := e.authorize(ctx, OpRead, form)
+	if err != nil {
+		return nil, err
+	}
+
+	// fetch one more row than requested to find out if there is a next page
+	var probe Pageable
+	if e.UsesKeysetPagination() {
+		probe = KeysetPaging{Value: pageable.KeysetValue(), PageSize: pageable.Size() + 1}
+	} else {
+		probe = OffsetPaging{PageOffset: pageable.Offset(), PageSize: pageable.Size() + 1}
+	}
+
+	rows, err := e.read(ctx, form, db, predicates, probe)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &Page{Total: -1, Size: pageable.Size(), Number: pageNumber(pageable)}
+	if len(rows) > pageable.Size() {
+		rows = rows[:pageable.Size()]
+		page.HasMore = true
+	}
+	page.Rows = rows
+
+	if withTotal {
+		total, err := e.count(ctx, form, db, predicates)
+		if err != nil {
+			return nil, err
+		}
+		page.Total = total
+	}
+
+	if page.HasMore {
+		if e.UsesKeysetPagination() {
+			last := rows[len(rows)-1]
+			page.next = &KeysetPaging{Value: last.Get(e.keysetPaginationField), PageSize: page.Size, PageNumber: page.Number + 1}
+		} else {
+			page.next = &OffsetPaging{PageOffset: pageable.Offset() + page.Size, PageSize: page.Size}
+		}
+	}
+
+	if err := e.loadIncluded(ctx, db, page.Rows); err != nil {
+		return nil, err
+	}
+	e.invokePostActionCallback(e.postRead, page.Rows)
+	return page, nil
+}
+
+// ReadIter executes the bulk selection and returns an iterator that scans rows lazily.
+// The post-read callback is invoked for every chunk of rows scanned by the iterator.
+func (e Editor) ReadIter(form DataForm, db *sql.DB, pageable ...Pageable) (*RowIterator, error) {
+	return e.ReadIterContext(context.Background(), form, db, pageable...)
+}
+
+func (e Editor) ReadIterContext(ctx context.Context, form DataForm, db *sql.DB, pageable ...Pageable) (*RowIterator, error) {
+	e = e.forRole(ctx)
+	if err := e.checkOperation(OpRead); err != nil {
+		return nil, err
+	}
 	e.invokePreActionCallback(e.preRead, form)
-	fieldValues := e.getFieldValues(e.filterFields, form)
+	predicates, err := e.authorize(ctx, OpRead, form)
+	if err != nil {
+		return nil, err
+	}
+	fieldValues, err := e.readValues(ctx, form, pageable...)
+	if err != nil {
+		return nil, err
+	}
+	query, fieldValues := e.readStatement.with(e.dialect, fieldValues, predicates)
+	rows, err := db.QueryContext(ctx, query, fieldValues...)
+	if err != nil {
+		return nil, err
+	}
+	it, err := newRowIterator(e, rows)
+	if err != nil {
+		rows.Close()
+		return nil, err
+	}
+	return it, nil
+}
+
+func (e Editor) read(ctx context.Context, form DataForm, db *sql.DB, predicates []Predicate, pageable ...Pageable) ([]DbRow, error) {
+	return e.readWith(ctx, db, e.readStatement, form, predicates, pageable...)
+}
+
+// Executes the given bulk selection statement
+func (e Editor) readWith(ctx context.Context, q queryer, s statement, form DataForm, predicates []Predicate, pageable ...Pageable) ([]DbRow, error) {
+	fieldValues, err := e.readValues(ctx, form, pageable...)
+	if err != nil {
+		return nil, err
+	}
+	query, fieldValues := s.with(e.dialect, fieldValues, predicates)
+	rows, err := q.QueryContext(ctx, query, fieldValues...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return e.scanRows(rows)
+}
+
+// Returns the bulk selection parameter values, including pagination
+func (e Editor) readValues(ctx context.Context, form DataForm, pageable ...Pageable) ([]any, error) {
+	fieldValues, err := e.getFieldValues(ctx, e.filterFields, form)
+	if err != nil {
+		return nil, err
+	}
 
 	if len(pageable) != 0 {
 		p := pageable[0]
@@ -660,59 +1274,129 @@ func (e Editor) Read(form DataForm, db *sql.DB, pageable ...Pageable) ([]DbRow,
 			fieldValues = append(fieldValues, p.Offset(), p.Size())
 		}
 	}
+	return fieldValues, nil
+}
 
-	switch e.dialect {
-	case MYSQL:
-		fallthrough
-	case SQLITE:
-		fallthrough
-	case POSTGRESQL:
-		rows, err := db.Query(e.readStatement, fieldValues...)
-		if err != nil {
-			return nil, err
-		}
-		results, err = e.scanRows(rows)
-		if err != nil {
-			return nil, err
-		}
+// Count returns the number of rows matching the selection filters
+func (e Editor) Count(form DataForm, db *sql.DB) (int64, error) {
+	return e.CountContext(context.Background(), form, db)
+}
+
+func (e Editor) CountContext(ctx context.Context, form DataForm, db *sql.DB) (int64, error) {
+	e = e.forRole(ctx)
+	if err := e.checkOperation(OpRead); err != nil {
+		return 0, err
 	}
-	e.invokePostActionCallback(e.postRead, results)
-	return results, nil
+	e.invokePreActionCallback(e.preRead, form)
+	predicates, err := e.authorize(ctx, OpRead, form)
+	if err != nil {
+		return 0, err
+	}
+	return e.count(ctx, form, db, predicates)
+}
 
+// Exists returns whether any row matches the selection filters
+func (e Editor) Exists(form DataForm, db *sql.DB) (bool, error) {
+	return e.ExistsContext(context.Background(), form, db)
+}
+
+func (e Editor) ExistsContext(ctx context.Context, form DataForm, db *sql.DB) (bool, error) {
+	e = e.forRole(ctx)
+	if err := e.checkOperation(OpRead); err != nil {
+		return false, err
+	}
+	var exists bool
+	e.invokePreActionCallback(e.preRead, form)
+	predicates, err := e.authorize(ctx, OpRead, form)
+	if err != nil {
+		return false, err
+	}
+	fieldValues, err := e.getFieldValues(ctx, e.filterFields, form)
+	if err != nil {
+		return false, err
+	}
+	query, fieldValues := e.existsStatement.with(e.dialect, fieldValues, predicates)
+	if err := db.QueryRowContext(ctx, query, fieldValues...).Scan(&exists); err != nil {
+		return false, err
+	}
+	return exists, nil
+}
+
+func (e Editor) count(ctx context.Context, form DataForm, db *sql.DB, predicates []Predicate) (int64, error) {
+	var total int64
+	fieldValues, err := e.getFieldValues(ctx, e.filterFields, form)
+	if err != nil {
+		return 0, err
+	}
+	query, fieldValues := e.countStatement.with(e.dialect, fieldValues, predicates)
+	if err := db.QueryRowContext(ctx, query, fieldValues...).Scan(&total); err != nil {
+		return 0, err
+	}
+	return total, nil
 }
 
 func (e Editor) Update(form DataForm, db *sql.DB) (DbRow, error) {
+	return e.UpdateContext(context.Background(), form, db)
+}
+
+func (e Editor) UpdateContext(ctx context.Context, form DataForm, db *sql.DB) (DbRow, error) {
+	e = e.forRole(ctx)
+	if err := e.checkOperation(OpUpdate); err != nil {
+		return nil, err
+	}
 	var results DbRow
 
+	if err := e.checkFormKeys(OpUpdate, form); err != nil {
+		return nil, err
+	}
 	e.invokePreActionCallback(e.preUpdate, form)
-	fieldValues := e.getFieldValues(e.updateFields, form)
+	predicates, err := e.authorize(ctx, OpUpdate, form)
+	if err != nil {
+		return nil, err
+	}
+	if err := e.validate(OpUpdate, form); err != nil {
+		return nil, err
+	}
+	fieldValues, err := e.getWriteValues(ctx, OpUpdate, form)
+	if err != nil {
+		return nil, err
+	}
 
-	pkv := e.getFieldvalue(e.primaryKeyField, form)
-	fieldValues = append(fieldValues, pkv)
+	selectionValues, err := e.getSingleSelectionValues(ctx, form)
+	if err != nil {
+		return nil, err
+	}
+	fieldValues = append(fieldValues, selectionValues...)
 
-	if len(e.filterFields) > 0 {
-		fieldValues = append(fieldValues, e.getFieldValues(e.filterFields, form)...)
+	versionValues, err := e.getVersionValues(form)
+	if err != nil {
+		return nil, err
 	}
+	fieldValues = append(fieldValues, versionValues...)
+	query, fieldValues := e.updateStatement.with(e.dialect, fieldValues, predicates)
 
 	switch e.dialect {
 	case SQLITE:
 		fallthrough
 	case MYSQL:
-		_, err := db.Exec(e.updateStatement, fieldValues...)
+		res, err := db.ExecContext(ctx, query, fieldValues...)
 		if err != nil {
 			return nil, err
 		}
-		result, err := e.SingleRead(form, db)
+		if err := e.checkAffected(res); err != nil {
+			return nil, err
+		}
+		result, err := e.singleRead(ctx, form, db, predicates)
 		if err != nil {
 			return nil, err
 		}
 		results = result
 	case POSTGRESQL:
-		rows, err := db.Query(e.updateStatement, fieldValues...)
+		rows, err := db.QueryContext(ctx, query, fieldValues...)
 		if err != nil {
 			return nil, err
 		}
-		results, err = e.scanRow(rows)
+		results, err = e.scanReturnedRow(rows)
 		if err != nil {
 			return nil, err
 		}
@@ -722,56 +1406,115 @@ func (e Editor) Update(form DataForm, db *sql.DB) (DbRow, error) {
 }
 
 func (e Editor) Delete(form DataForm, db *sql.DB) (DbRow, error) {
+	return e.DeleteContext(context.Background(), form, db)
+}
+
+func (e Editor) DeleteContext(ctx context.Context, form DataForm, db *sql.DB) (DbRow, error) {
+	e = e.forRole(ctx)
+	if err := e.checkOperation(OpDelete); err != nil {
+		return nil, err
+	}
+	if e.softDelete && e.cascades(false) {
+		var row DbRow
+		err := e.inTransaction(ctx, db, func(tx *sql.Tx) (err error) {
+			row, err = e.delete(ctx, form, tx)
+			return err
+		})
+		return row, err
+	}
+	return e.delete(ctx, form, db)
+}
+
+// Deletes the row, executing the statements with q
+func (e Editor) delete(ctx context.Context, form DataForm, q queryer) (DbRow, error) {
 	var results DbRow
 	var fieldValues []any
+	var affected bool
 
 	e.invokePreActionCallback(e.preDelete, form)
+	predicates, err := e.authorize(ctx, OpDelete, form)
+	if err != nil {
+		return nil, err
+	}
 
+	at := e.now()
 	if e.softDelete {
-		fieldValues = e.getSoftDeletionValues(form)
+		fieldValues = e.getSoftDeletionValues(at)
 	}
 
-	fieldValues = append(fieldValues, e.getFieldvalue(e.primaryKeyField, form))
-	if len(e.filterFields) > 0 {
-		fieldValues = append(fieldValues, e.getFieldValues(e.filterFields, form)...)
+	selectionValues, err := e.getSingleSelectionValues(ctx, form)
+	if err != nil {
+		return nil, err
+	}
+	fieldValues = append(fieldValues, selectionValues...)
+
+	versionValues, err := e.getVersionValues(form)
+	if err != nil {
+		return nil, err
 	}
+	fieldValues = append(fieldValues, versionValues...)
+	query, fieldValues := e.deleteStatement.with(e.dialect, fieldValues, predicates)
 
 	switch e.dialect {
 	case SQLITE:
 		fallthrough
 	case MYSQL:
 		if e.softDelete {
-			_, err := db.Exec(e.deleteStatement, fieldValues...)
+			res, err := q.ExecContext(ctx, query, fieldValues...)
 			if err != nil {
 				return nil, err
 			}
-			result, err := e.SingleRead(form, db)
+			if err := e.checkAffected(res); err != nil {
+				return nil, err
+			}
+			if affected, err = rowsAffected(res); err != nil {
+				return nil, err
+			}
+			result, err := e.singleRead(ctx, form, q, predicates)
 			if err != nil {
 				return nil, err
 			}
 			results = result
 		} else {
-			_, err := db.Exec(e.deleteStatement, fieldValues...)
+			res, err := q.ExecContext(ctx, query, fieldValues...)
 			if err != nil {
 				return nil, err
 			}
+			if err := e.checkAffected(res); err != nil {
+				return nil, err
+			}
 			// copy field values over since we cannot get the values back after deletion
 			results = make(DbRow)
 			for _, f := range e.readFields {
 				f = e.unquote(f)
+				if e.redacted[f] {
+					continue
+				}
+				if f == e.tenantField {
+					if results[f], err = e.tenantValue(ctx); err != nil {
+						return nil, err
+					}
+					continue
+				}
 				results[f] = form.Get(f)
 			}
 		}
 	case POSTGRESQL:
-		rows, err := db.Query(e.deleteStatement, fieldValues...)
+		rows, err := q.QueryContext(ctx, query, fieldValues...)
 		if err != nil {
 			return nil, err
 		}
-		results, err = e.scanRow(rows)
+		results, affected, err = e.scanAffectedRow(rows)
 		if err != nil {
 			return nil, err
 		}
 	}
+	// the dependent rows of a row the caller may not delete are left untouched
+	if e.softDelete && affected {
+		if err := e.cascade(ctx, q, form, at, false); err != nil {
+			return nil, err
+		}
+	}
 	e.invokePostActionCallback(e.postDelete, []DbRow{results})
 	return results, nil
 }
@@ -795,8 +1538,46 @@ type Field struct {
 	//	editor.Read() // SELECT "name", school_id FROM students WHERE school_id = $1
 	SelectionFilter bool
 	NullCheck       FieldNullCheck
-	SoftDelete      bool      // Indicates whether this field should be used when soft-deleting records
-	SoftDeleteType  FieldType // Indicates the type of the soft deletion field.
+	SoftDelete      bool        // Indicates whether this field should be used when soft-deleting records
+	SoftDeleteType  FieldType   // Indicates the type of the soft deletion field.
+	Type            FieldType   // Declared type of the column, used to coerce form values and normalize values read from the database
+	TypeDeclared    bool        // Indicates whether Type has been declared. See 'OfType'
+	Validators      []Validator // Rules checked by Create and Update before any statement is executed
+	// Set to the current time on create. The value is never read from the form
+	AutoCreateTimestamp bool
+	// Set to the current time on update. The value is never read from the form
+	AutoUpdateTimestamp bool
+	// Provides the value written on create. The value is never read from the form
+	CreateValueProvider ValueProvider
+	// Provides the value written on update. The value is never read from the form
+	UpdateValueProvider ValueProvider
+	WriteTransformers   []WriteTransformer // Applied to values before they are bound to statements
+	ReadTransformers    []ReadTransformer  // Applied to values after they are scanned
+	Redacted            bool               // Removes the field from the rows returned by the editor
+	Keyring             *Keyring           // Encrypts the values of the field when set. See 'Encrypted()'
+	Version             bool               // Version column used for optimistic locking. See 'IsVersion'
+	ReadRoles           []string           // Roles allowed to read the field, all when empty. See 'ReadableBy()'
+	CreateRoles         []string           // Roles allowed to write the field on create, all when empty
+	UpdateRoles         []string           // Roles allowed to write the field on update, all when empty
+	DecodeJSON          bool               // Decodes the JSON values read from the column. See 'IsJSON'
+	JSONColumn          string             // JSON column holding the value of a filter. See 'FromJSONPath()'
+	JSONPath            []string           // Path of the value of a filter in JSONColumn
+}
+
+// Reports whether the field is set to the current time by the operation
+func (f Field) autoTimestampOn(op Operation) bool {
+	return (op == OpCreate && f.AutoCreateTimestamp) || (op == OpUpdate && f.AutoUpdateTimestamp)
+}
+
+// Reports whether the value written by the operation is set by the editor rather than the client
+func (f Field) managedOn(op Operation) bool {
+	switch op {
+	case OpCreate:
+		return f.AutoCreateTimestamp || f.CreateValueProvider != nil || f.Version
+	case OpUpdate:
+		return f.AutoUpdateTimestamp || f.UpdateValueProvider != nil || f.Version
+	}
+	return false
 }
 
 // Indicates the type of column the field represents, mainly used when soft-deleting.
@@ -812,8 +1593,45 @@ const (
 	IntField FieldType = iota
 	BoolField
 	TimestampField
+	StringField
+	FloatField
+	JsonField
+	BytesField
+	DateField
+	UUIDField
+	DecimalField
 )
 
+// The type of a value whose type is not known, which is returned as is by 'CoerceValue()' and
+// 'NormalizeValue()'
+const UnknownField FieldType = -1
+
+func (t FieldType) String() string {
+	switch t {
+	case IntField:
+		return "int"
+	case BoolField:
+		return "bool"
+	case TimestampField:
+		return "timestamp"
+	case StringField:
+		return "string"
+	case FloatField:
+		return "float"
+	case JsonField:
+		return "json"
+	case BytesField:
+		return "bytes"
+	case DateField:
+		return "date"
+	case UUIDField:
+		return "uuid"
+	case DecimalField:
+		return "decimal"
+	}
+	return "unknown"
+}
+
 // Indicates whether a field should be checked againt the 'NULL' constant value.
 //
 // For most DBMS, the following query will yield nothing:
@@ -839,6 +1657,10 @@ const (
 
 type FieldOption func(f *Field)
 
+// ValueProvider computes the value of a field on create or update from the context and the
+// form. A returned error aborts the operation.
+type ValueProvider func(ctx context.Context, form DataForm) (any, error)
+
 var (
 	IsPrimaryKey  FieldOption = func(f *Field) { f.PrimaryKey = true }
 	IncludeAlways FieldOption = func(f *Field) {
@@ -854,6 +1676,38 @@ var (
 	IsNullConstant    FieldOption = func(f *Field) { f.NullCheck = FieldMustBeNull }
 	IsNotNullConstant FieldOption = func(f *Field) { f.NullCheck = FieldMustNotBeNull }
 
+	// Declares the type of the column. Form values are coerced into that type before being bound
+	// to statements. See 'CoerceValue()' and 'Editor.NormalizeValues()'
+	OfType = func(t FieldType) FieldOption {
+		return func(f *Field) {
+			f.Type = t
+			f.TypeDeclared = true
+		}
+	}
+
+	// Sets the field to the current time when rows are created. See 'Editor.SetClock()' and
+	// 'Editor.SQLTimestamps()'
+	AutoCreateTimestamp FieldOption = func(f *Field) { f.AutoCreateTimestamp = true }
+	// Sets the field to the current time when rows are updated. Combine with AutoCreateTimestamp
+	// to also set the field when rows are created.
+	AutoUpdateTimestamp FieldOption = func(f *Field) { f.AutoUpdateTimestamp = true }
+
+	// Writes the value returned by the provider on create, ignoring the form
+	ProvideOnCreate = func(p ValueProvider) FieldOption {
+		return func(f *Field) { f.CreateValueProvider = p }
+	}
+	// Writes the value returned by the provider on update, ignoring the form
+	ProvideOnUpdate = func(p ValueProvider) FieldOption {
+		return func(f *Field) { f.UpdateValueProvider = p }
+	}
+	// Writes the value returned by the provider on both create and update, ignoring the form
+	ProvideAlways = func(p ValueProvider) FieldOption {
+		return func(f *Field) {
+			f.CreateValueProvider = p
+			f.UpdateValueProvider = p
+		}
+	}
+
 	SoftDeleteAs = func(t FieldType) FieldOption {
 		return func(f *Field) {
 			f.SoftDelete = true
@@ -162,6 +162,27 @@ func TestPostgresqlRead(t *testing.T) {
 	require.Equal(t, 10, len(rows))
 }
 
+func TestPostgresqlReadPage(t *testing.T) {
+	db, err := getPgConnection()
+	checkError(err, t)
+	defer db.Close()
+
+	checkError(seedDb("testdata/pg_seed.sql", db), t)
+
+	form := crudiator.MapBackedDataForm{"school_id": 1}
+
+	page, err := studentCrudiator.ReadPage(form, db, crudiator.NewKeysetPaging(0, 10), true)
+	checkError(err, t)
+	require.Equal(t, 10, len(page.Rows))
+	require.True(t, page.HasMore)
+	require.Equal(t, int64(1000), page.Total)
+
+	page, err = studentCrudiator.ReadPage(form, db, page.Next(), false)
+	checkError(err, t)
+	require.Equal(t, 1, page.Number)
+	require.Equal(t, int64(11), page.Rows[0]["id"])
+}
+
 func TestPostgresqlUpdate(t *testing.T) {
 	db, err := getPgConnection()
 	checkError(err, t)
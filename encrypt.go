@@ -0,0 +1,299 @@
+package crudiator
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Separates the key ID from the encrypted payload
+const keyIDSeparator = ":"
+
+// Keyring holds the AES keys used to encrypt field values, identified by key ID. Values are
+// encrypted with the current key and decrypted with the key named by their prefix.
+type Keyring struct {
+	current string
+	aeads   map[string]cipher.AEAD
+}
+
+// Creates a keyring from AES-128, AES-192 or AES-256 keys keyed by ID. current is the ID of the
+// key used to encrypt values.
+func NewKeyring(current string, keys map[string][]byte) (*Keyring, error) {
+	k := &Keyring{current: current, aeads: make(map[string]cipher.AEAD)}
+	for id, key := range keys {
+		if id == "" || strings.Contains(id, keyIDSeparator) {
+			return nil, errors.Errorf("invalid key ID '%s'", id)
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, errors.Wrapf(err, "key '%s'", id)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, errors.Wrapf(err, "key '%s'", id)
+		}
+		k.aeads[id] = aead
+	}
+	if _, ok := k.aeads[current]; !ok {
+		return nil, errors.Errorf("current key '%s' not found", current)
+	}
+	return k, nil
+}
+
+// Same as 'NewKeyring()' but panics on error
+func MustNewKeyring(current string, keys map[string][]byte) *Keyring {
+	k, err := NewKeyring(current, keys)
+	if err != nil {
+		panic(err)
+	}
+	return k
+}
+
+// Encrypts the plaintext with the current key. The result is the key ID followed by the base64
+// encoded nonce and sealed payload. i.e. 'k2:Zm9v...'
+func (k *Keyring) Encrypt(plaintext []byte) (string, error) {
+	aead := k.aeads[k.current]
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, plaintext, nil)
+	return k.current + keyIDSeparator + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypts a value produced by 'Encrypt()' with the key named by its prefix
+func (k *Keyring) Decrypt(ciphertext string) ([]byte, error) {
+	id, payload, err := k.split(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	aead, ok := k.aeads[id]
+	if !ok {
+		return nil, errors.Errorf("unknown key '%s'", id)
+	}
+	sealed, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, errors.Wrap(err, "malformed ciphertext")
+	}
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("malformed ciphertext")
+	}
+	nonce, sealed := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	return aead.Open(nil, nonce, sealed, nil)
+}
+
+// Reports whether the value was encrypted with the current key
+func (k *Keyring) IsCurrent(ciphertext string) bool {
+	id, _, err := k.split(ciphertext)
+	return err == nil && id == k.current
+}
+
+func (k *Keyring) split(ciphertext string) (string, string, error) {
+	id, payload, found := strings.Cut(ciphertext, keyIDSeparator)
+	if !found {
+		return "", "", errors.New("ciphertext has no key ID")
+	}
+	return id, payload, nil
+}
+
+// Encrypts the value of the field when it is written and decrypts it, as a string, when it is
+// read. Build() panics if the field is the primary key, a filter or the keyset field.
+func Encrypted(k *Keyring) FieldOption {
+	return func(f *Field) { f.Keyring = k }
+}
+
+func (e Editor) encrypt(field string, value any) (any, error) {
+	k := e.fieldsByName[field].Keyring
+	if k == nil || value == nil {
+		return value, nil
+	}
+	plaintext, err := asString(value)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to encrypt value of '%s'", field)
+	}
+	return k.Encrypt([]byte(plaintext))
+}
+
+func decrypter(k *Keyring) valueDecoder {
+	return func(value any) (any, error) {
+		if value == nil {
+			return nil, nil
+		}
+		ciphertext, err := asString(value)
+		if err != nil {
+			return nil, err
+		}
+		plaintext, err := k.Decrypt(ciphertext)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to decrypt")
+		}
+		return string(plaintext), nil
+	}
+}
+
+func (e *Editor) checkEncryptedFields() *Editor {
+	for _, f := range e.fields {
+		if f.Keyring == nil {
+			continue
+		}
+		if f.PrimaryKey || f.SelectionFilter || (e.pagination == KEYSET && f.Name == e.keysetPaginationField) {
+			panic(errors.Errorf("encrypted field '%s' cannot be used to select rows", f.Name))
+		}
+	}
+	return e
+}
+
+// ReEncrypt re-encrypts with the current key the values of every row, soft deleted or not, that
+// were encrypted with another key, in batches of batchSize rows. Returns the number of updated rows.
+func (e Editor) ReEncrypt(ctx context.Context, db *sql.DB, batchSize int) (int64, error) {
+	var updated int64
+	var cursor any
+
+	if err := e.checkOperation(OpUpdate); err != nil {
+		return 0, err
+	}
+
+	if !e.UsesKeysetPagination() {
+		return 0, errors.New("re-encryption requires keyset pagination")
+	}
+	if batchSize <= 0 {
+		return 0, errors.New("batch size must be positive")
+	}
+
+	var encrypted []Field
+	for _, f := range e.fields {
+		if f.Keyring != nil {
+			encrypted = append(encrypted, f)
+		}
+	}
+	if len(encrypted) == 0 {
+		return 0, nil
+	}
+
+	columns := []string{e.quote(e.primaryKeyField), e.quote(e.keysetPaginationField)}
+	assignments := make([]string, len(encrypted))
+	for i, f := range encrypted {
+		columns = append(columns, e.quote(f.Name))
+		assignments[i] = e.quote(f.Name) + "=" + placeholder(e.dialect, i+1)
+	}
+	selection := "SELECT " + strings.Join(columns, ",") + " FROM " + e.tableNameQuoted
+	order := " ORDER BY " + e.quote(e.keysetPaginationField) + " ASC LIMIT "
+	first := selection + order + placeholder(e.dialect, 1)
+	next := selection + " WHERE " + e.quote(e.keysetPaginationField) + ">" + placeholder(e.dialect, 1) +
+		order + placeholder(e.dialect, 2)
+	conditions := []string{e.quote(e.primaryKeyField) + "=" + placeholder(e.dialect, len(encrypted)+1)}
+	for i, f := range encrypted {
+		conditions = append(conditions, e.quote(f.Name)+e.nullSafeEqual()+placeholder(e.dialect, len(encrypted)+i+2))
+	}
+	update := "UPDATE " + e.tableNameQuoted + " SET " + strings.Join(assignments, ",") +
+		" WHERE " + strings.Join(conditions, " AND ")
+
+	for {
+		var rows *sql.Rows
+		var err error
+		if cursor == nil {
+			rows, err = db.QueryContext(ctx, first, batchSize)
+		} else {
+			rows, err = db.QueryContext(ctx, next, cursor, batchSize)
+		}
+		if err != nil {
+			return updated, err
+		}
+		batch, err := scanValues(rows, len(columns))
+		if err != nil {
+			return updated, err
+		}
+
+		for _, values := range batch {
+			args, stale, err := reEncryptValues(encrypted, values[2:])
+			if err != nil {
+				return updated, errors.Wrapf(err, "row %v", values[0])
+			}
+			if !stale {
+				continue
+			}
+			args = append(append(args, values[0]), values[2:]...)
+			res, err := db.ExecContext(ctx, update, args...)
+			if err != nil {
+				return updated, err
+			}
+			affected, err := res.RowsAffected()
+			if err != nil {
+				return updated, err
+			}
+			// otherwise the row has been written since it was read
+			updated += affected
+		}
+
+		if len(batch) < batchSize {
+			return updated, nil
+		}
+		cursor = batch[len(batch)-1][1]
+	}
+}
+
+// Returns the operator comparing two values that are equal when both are NULL
+func (e Editor) nullSafeEqual() string {
+	switch e.dialect {
+	case POSTGRESQL:
+		return " IS NOT DISTINCT FROM "
+	case MYSQL:
+		return "<=>"
+	}
+	return " IS "
+}
+
+// Scans every row of the result set as a slice of values and closes it
+func scanValues(rows *sql.Rows, count int) ([][]any, error) {
+	defer rows.Close()
+	var result [][]any
+	for rows.Next() {
+		values := make([]any, count)
+		pointers := make([]any, count)
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, err
+		}
+		result = append(result, values)
+	}
+	return result, rows.Err()
+}
+
+// Returns the values of the encrypted fields, re-encrypted with their current key, and whether
+// any of them was encrypted with another key
+func reEncryptValues(fields []Field, values []any) ([]any, bool, error) {
+	var stale bool
+	args := make([]any, len(fields))
+	for i, f := range fields {
+		args[i] = values[i]
+		if values[i] == nil {
+			continue
+		}
+		ciphertext, err := asString(values[i])
+		if err != nil {
+			return nil, false, err
+		}
+		args[i] = ciphertext
+		if f.Keyring.IsCurrent(ciphertext) {
+			continue
+		}
+		plaintext, err := f.Keyring.Decrypt(ciphertext)
+		if err != nil {
+			return nil, false, errors.Wrapf(err, "failed to decrypt '%s'", f.Name)
+		}
+		if args[i], err = f.Keyring.Encrypt(plaintext); err != nil {
+			return nil, false, err
+		}
+		stale = true
+	}
+	return args, stale, nil
+}
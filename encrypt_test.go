@@ -0,0 +1,139 @@
+package crudiator_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"strings"
+	"testing"
+
+	"github.com/SharkFourSix/crudiator"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyring(t *testing.T) {
+	k1 := crudiator.MustNewKeyring("k1", map[string][]byte{"k1": bytes.Repeat([]byte{1}, 32)})
+	ciphertext, err := k1.Encrypt([]byte("123-45-6789"))
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(ciphertext, "k1:"))
+	require.NotContains(t, ciphertext, "6789")
+
+	plaintext, err := k1.Decrypt(ciphertext)
+	require.NoError(t, err)
+	require.Equal(t, "123-45-6789", string(plaintext))
+
+	_, err = k1.Decrypt(ciphertext[:len(ciphertext)-4] + "AAAA")
+	require.Error(t, err)
+
+	k2 := crudiator.MustNewKeyring("k2", map[string][]byte{"k1": bytes.Repeat([]byte{1}, 32), "k2": bytes.Repeat([]byte{2}, 16)})
+	require.False(t, k2.IsCurrent(ciphertext))
+	plaintext, err = k2.Decrypt(ciphertext)
+	require.NoError(t, err)
+	require.Equal(t, "123-45-6789", string(plaintext))
+
+	_, err = crudiator.NewKeyring("k1", map[string][]byte{"k1": []byte("short")})
+	require.Error(t, err)
+	_, err = crudiator.NewKeyring("k3", map[string][]byte{"k1": bytes.Repeat([]byte{1}, 32)})
+	require.Error(t, err)
+}
+
+func TestEncryptedField(t *testing.T) {
+	db, fdb := newFakeDb(t)
+	k1 := crudiator.MustNewKeyring("k1", map[string][]byte{"k1": bytes.Repeat([]byte{1}, 32)})
+	editor := newStudentEditor(crudiator.POSTGRESQL, crudiator.NewField("national_id", crudiator.IncludeAlways, crudiator.Encrypted(k1))).
+		MustPaginate(crudiator.KEYSET, "id").Build()
+
+	fdb.queueRows([]string{"id", "name", "national_id"}, []driver.Value{int64(1), "Jane", nil})
+	_, err := editor.Create(crudiator.MapBackedDataForm{"name": "Jane", "national_id": "123-45-6789"}, db)
+	require.NoError(t, err)
+	args := fdb.last().Args
+	require.Equal(t, "Jane", args[0])
+	ciphertext := args[1].(string)
+	require.True(t, strings.HasPrefix(ciphertext, "k1:"))
+
+	fdb.queueRows([]string{"id", "name", "national_id"}, []driver.Value{int64(1), "Jane", []byte(ciphertext)})
+	rows, err := editor.Read(crudiator.MapBackedDataForm{}, db, crudiator.NewKeysetPaging(0, 10))
+	require.NoError(t, err)
+	require.Equal(t, "123-45-6789", rows[0]["national_id"])
+
+	fdb.queueRows([]string{"id", "name", "national_id"}, []driver.Value{int64(1), "Jane", []byte("k1:garbage")})
+	_, err = editor.Read(crudiator.MapBackedDataForm{}, db, crudiator.NewKeysetPaging(0, 10))
+	require.ErrorContains(t, err, "national_id")
+
+	require.Panics(t, func() {
+		newStudentEditor(
+			crudiator.POSTGRESQL,
+			crudiator.NewField("national_id", crudiator.IncludeAlways, crudiator.IsSelectionFilter, crudiator.Encrypted(k1)),
+		).Build()
+	})
+}
+
+func TestReEncrypt(t *testing.T) {
+	db, fdb := newFakeDb(t)
+	keys := map[string][]byte{"k1": bytes.Repeat([]byte{1}, 32)}
+	k1 := crudiator.MustNewKeyring("k1", keys)
+	old1, _ := k1.Encrypt([]byte("111"))
+	old2, _ := k1.Encrypt([]byte("222"))
+
+	keys["k2"] = bytes.Repeat([]byte{2}, 32)
+	k2 := crudiator.MustNewKeyring("k2", keys)
+	current, _ := k2.Encrypt([]byte("333"))
+	editor := newStudentEditor(crudiator.POSTGRESQL, crudiator.NewField("national_id", crudiator.IncludeAlways, crudiator.Encrypted(k2))).
+		MustPaginate(crudiator.KEYSET, "id").Build()
+
+	cols := []string{"id", "id", "national_id"}
+	fdb.queueRows(cols, []driver.Value{int64(1), int64(1), old1}, []driver.Value{int64(2), int64(2), current})
+	fdb.queue(oneRowAffected)
+	fdb.queueRows(cols, []driver.Value{int64(3), int64(3), nil}, []driver.Value{int64(4), int64(4), old2})
+	fdb.queue(oneRowAffected)
+	fdb.queueRows(cols)
+
+	updated, err := editor.ReEncrypt(context.Background(), db, 2)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), updated)
+
+	queries := fdb.all()
+	require.Len(t, queries, 5)
+	require.Equal(t, `SELECT "id","id","national_id" FROM "students" ORDER BY "id" ASC LIMIT $1`, queries[0].Query)
+	require.Equal(t, `UPDATE "students" SET "national_id"=$1 WHERE "id"=$2 AND "national_id" IS NOT DISTINCT FROM $3`, queries[1].Query)
+	require.Equal(t, []any{int64(1), old1}, queries[1].Args[1:])
+	require.Equal(t, `SELECT "id","id","national_id" FROM "students" WHERE "id">$1 ORDER BY "id" ASC LIMIT $2`, queries[2].Query)
+	require.Equal(t, []any{int64(2), 2}, queries[2].Args)
+	require.Equal(t, []any{int64(4), 2}, queries[4].Args)
+
+	for i, expected := range map[int]string{1: "111", 3: "222"} {
+		ciphertext := queries[i].Args[0].(string)
+		require.True(t, k2.IsCurrent(ciphertext))
+		plaintext, err := k2.Decrypt(ciphertext)
+		require.NoError(t, err)
+		require.Equal(t, expected, string(plaintext))
+	}
+}
+
+func TestReEncryptSkipsConcurrentWrites(t *testing.T) {
+	db, fdb := newFakeDb(t)
+	keys := map[string][]byte{"k1": bytes.Repeat([]byte{1}, 32)}
+	k1 := crudiator.MustNewKeyring("k1", keys)
+	old1, _ := k1.Encrypt([]byte("111"))
+	old2, _ := k1.Encrypt([]byte("222"))
+
+	keys["k2"] = bytes.Repeat([]byte{2}, 32)
+	k2 := crudiator.MustNewKeyring("k2", keys)
+	editor := newStudentEditor(crudiator.POSTGRESQL, crudiator.NewField("national_id", crudiator.IncludeAlways, crudiator.Encrypted(k2))).
+		MustPaginate(crudiator.KEYSET, "id").Build()
+
+	// the first row is written by someone else between the read and the update
+	cols := []string{"id", "id", "national_id"}
+	fdb.queueRows(cols, []driver.Value{int64(1), int64(1), old1}, []driver.Value{int64(2), int64(2), old2})
+	fdb.queue(fakeResult{RowsAffected: 0})
+	fdb.queue(oneRowAffected)
+
+	updated, err := editor.ReEncrypt(context.Background(), db, 10)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), updated)
+
+	queries := fdb.all()
+	require.Len(t, queries, 3)
+	require.Equal(t, []any{int64(1), old1}, queries[1].Args[1:])
+	require.Equal(t, []any{int64(2), old2}, queries[2].Args[1:])
+}
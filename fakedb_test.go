@@ -0,0 +1,216 @@
+package crudiator_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// fakeDb is a minimal database/sql driver used to test statement generation and row handling
+// without a running database. Each executed statement is recorded and answered with the next
+// queued result.
+type fakeDb struct {
+	mu       sync.Mutex
+	queries  []fakeQuery
+	results  []fakeResult
+	repeat   *fakeResult       // returned when no result is queued
+	columnDb map[string]string // column name => database type name
+	txEnds   []string          // "commit" or "rollback", in order
+}
+
+type fakeQuery struct {
+	Query string
+	Args  []any
+}
+
+type fakeResult struct {
+	Columns      []string
+	Rows         [][]driver.Value
+	RowsAffected int64
+	LastInsertId int64
+	Err          error
+}
+
+var (
+	fakeDbs      sync.Map
+	fakeDbSerial atomic.Int64
+)
+
+func init() {
+	sql.Register("crudiator-fake", fakeDriver{})
+}
+
+// newFakeDb opens a *sql.DB backed by a fresh fakeDb
+func newFakeDb(t testing.TB) (*sql.DB, *fakeDb) {
+	name := strconv.FormatInt(fakeDbSerial.Add(1), 10)
+	fdb := &fakeDb{columnDb: map[string]string{}}
+	fakeDbs.Store(name, fdb)
+	db, err := sql.Open("crudiator-fake", name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		db.Close()
+		fakeDbs.Delete(name)
+	})
+	return db, fdb
+}
+
+// queue adds a result to be returned by the next statement
+func (f *fakeDb) queue(r fakeResult) *fakeDb {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.results = append(f.results, r)
+	return f
+}
+
+// queueRows queues a result set with the given columns and rows
+func (f *fakeDb) queueRows(cols []string, rows ...[]driver.Value) *fakeDb {
+	return f.queue(fakeResult{Columns: cols, Rows: rows})
+}
+
+func (f *fakeDb) last() fakeQuery {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.queries) == 0 {
+		return fakeQuery{}
+	}
+	return f.queries[len(f.queries)-1]
+}
+
+func (f *fakeDb) all() []fakeQuery {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]fakeQuery(nil), f.queries...)
+}
+
+func (f *fakeDb) next(query string, args []driver.NamedValue) fakeResult {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	q := fakeQuery{Query: query}
+	for _, a := range args {
+		q.Args = append(q.Args, a.Value)
+	}
+	if f.repeat == nil {
+		f.queries = append(f.queries, q)
+	}
+	if len(f.results) == 0 {
+		if f.repeat != nil {
+			return *f.repeat
+		}
+		return fakeResult{}
+	}
+	r := f.results[0]
+	f.results = f.results[1:]
+	return r
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) {
+	v, ok := fakeDbs.Load(name)
+	if !ok {
+		return nil, driver.ErrBadConn
+	}
+	return &fakeConn{db: v.(*fakeDb)}, nil
+}
+
+type fakeConn struct {
+	db *fakeDb
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, driver.ErrSkip
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return fakeTx{db: c.db}, nil
+}
+
+func (c *fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	r := c.db.next(query, args)
+	if r.Err != nil {
+		return nil, r.Err
+	}
+	return &fakeRows{db: c.db, columns: r.Columns, rows: r.Rows}, nil
+}
+
+func (c *fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	r := c.db.next(query, args)
+	if r.Err != nil {
+		return nil, r.Err
+	}
+	return fakeExecResult{r}, nil
+}
+
+func (c *fakeConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if v, ok := nv.Value.(driver.Valuer); ok {
+		value, err := v.Value()
+		if err != nil {
+			return err
+		}
+		nv.Value = value
+	}
+	// accept anything else as is so tests can inspect the bound values
+	return nil
+}
+
+type fakeTx struct {
+	db *fakeDb
+}
+
+func (t fakeTx) Commit() error   { return t.end("commit") }
+func (t fakeTx) Rollback() error { return t.end("rollback") }
+
+func (t fakeTx) end(how string) error {
+	t.db.mu.Lock()
+	defer t.db.mu.Unlock()
+	t.db.txEnds = append(t.db.txEnds, how)
+	return nil
+}
+
+type fakeExecResult struct {
+	r fakeResult
+}
+
+func (r fakeExecResult) LastInsertId() (int64, error) { return r.r.LastInsertId, nil }
+func (r fakeExecResult) RowsAffected() (int64, error) { return r.r.RowsAffected, nil }
+
+type fakeRows struct {
+	db      *fakeDb
+	columns []string
+	rows    [][]driver.Value
+	pos     int
+}
+
+func (r *fakeRows) Columns() []string {
+	return r.columns
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.pos >= len(r.rows) {
+		return io.EOF
+	}
+	copy(dest, r.rows[r.pos])
+	r.pos++
+	return nil
+}
+
+func (r *fakeRows) ColumnTypeDatabaseTypeName(index int) string {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	return r.db.columnDb[r.columns[index]]
+}
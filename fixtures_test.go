@@ -0,0 +1,91 @@
+package crudiator_test
+
+import (
+	"context"
+
+	"github.com/SharkFourSix/crudiator"
+)
+
+type roleKey struct{}
+
+type tenantKey struct{}
+
+var oneRowAffected = fakeResult{RowsAffected: 1}
+
+var (
+	deletedAtFilter = crudiator.NewField("deleted_at", crudiator.IncludeOnRead, crudiator.IsSelectionFilter, crudiator.IsNullConstant)
+	schoolFilter    = crudiator.NewField("school_id", crudiator.IncludeOnRead, crudiator.IsSelectionFilter)
+)
+
+// Returns an editor of the students table, with the id and name fields followed by the given
+// fields, for the test to configure and build
+func newStudentEditor(dialect crudiator.SQLDialect, fields ...crudiator.Field) *crudiator.Editor {
+	return crudiator.MustNewEditor(
+		"students",
+		dialect,
+		append([]crudiator.Field{
+			crudiator.NewField("id", crudiator.IsPrimaryKey, crudiator.IncludeOnRead),
+			crudiator.NewField("name", crudiator.IncludeAlways),
+		}, fields...)...,
+	)
+}
+
+// Returns the tenant set on the context by the tests
+func tenantOf(ctx context.Context) any {
+	return ctx.Value(tenantKey{})
+}
+
+// Returns the role set on the context by the tests
+func roleOf(ctx context.Context) string {
+	role, _ := ctx.Value(roleKey{}).(string)
+	return role
+}
+
+func newCategoryEditor(dialect crudiator.SQLDialect) crudiator.Crudiator {
+	return crudiator.MustNewEditor(
+		"categories",
+		dialect,
+		crudiator.NewField("id", crudiator.IsPrimaryKey, crudiator.IncludeOnRead),
+		crudiator.NewField("name", crudiator.IncludeAlways),
+		crudiator.NewField("parent_id", crudiator.IncludeAlways),
+		crudiator.NewField("deleted_at", crudiator.IsSelectionFilter, crudiator.IsNullConstant),
+	).Hierarchy("parent_id").Build()
+}
+
+func newEmployeeEditor() crudiator.Crudiator {
+	return crudiator.MustNewEditor(
+		"employees",
+		crudiator.POSTGRESQL,
+		crudiator.NewField("id", crudiator.IsPrimaryKey, crudiator.IncludeOnRead),
+		crudiator.NewField("name", crudiator.IncludeAlways),
+		crudiator.NewField("salary", crudiator.IncludeAlways, crudiator.ReadableBy("hr"), crudiator.WritableBy("hr")),
+		crudiator.NewField("grade", crudiator.IncludeAlways, crudiator.UpdatableBy("hr")),
+	).RoleFrom(roleOf).Strict(true).Build()
+}
+
+func newSchoolEditors() (schools, students, courses *crudiator.Editor) {
+	schools = crudiator.MustNewEditor(
+		"schools",
+		crudiator.POSTGRESQL,
+		crudiator.NewField("id", crudiator.IsPrimaryKey, crudiator.IncludeOnRead),
+		crudiator.NewField("name", crudiator.IncludeAlways),
+	)
+	students = newStudentEditor(
+		crudiator.POSTGRESQL,
+		crudiator.NewField("school_id", crudiator.IncludeAlways),
+		crudiator.NewField("deleted_at", crudiator.IsSelectionFilter, crudiator.IsNullConstant),
+	)
+	courses = crudiator.MustNewEditor(
+		"courses",
+		crudiator.POSTGRESQL,
+		crudiator.NewField("id", crudiator.IsPrimaryKey, crudiator.IncludeOnRead),
+		crudiator.NewField("title", crudiator.IncludeAlways),
+	)
+	schools.HasMany("students", students, "school_id")
+	students.BelongsTo("school", schools, "school_id").
+		ManyToMany("courses", courses, "enrolments", "student_id", "course_id")
+	schools.Build()
+	students.Build()
+	courses.Build()
+	return schools, students, courses
+}
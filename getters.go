@@ -0,0 +1,134 @@
+package crudiator
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+// Returned, wrapped with the name of the value, by the getters when the value is missing or nil
+var ErrNoValue = errors.New("no value")
+
+// Getter is implemented by DataForm and DbRow, whose values are converted by the typed getters
+type Getter interface {
+	Get(name string) any
+}
+
+func lookup[T any](g Getter, name string, convert func(any) (T, error)) (T, error) {
+	var zero T
+	v := g.Get(name)
+	if v == nil {
+		return zero, errors.Wrapf(ErrNoValue, "'%s'", name)
+	}
+	t, err := convert(v)
+	if err != nil {
+		return zero, errors.Wrapf(err, "'%s'", name)
+	}
+	return t, nil
+}
+
+func lenient[T any](t T, _ error) T {
+	return t
+}
+
+func GetString(g Getter, name string) (string, error) {
+	return lookup(g, name, asString)
+}
+
+func GetInt64(g Getter, name string) (int64, error) {
+	return lookup(g, name, asInt64)
+}
+
+func GetFloat64(g Getter, name string) (float64, error) {
+	return lookup(g, name, asFloat64)
+}
+
+func GetBool(g Getter, name string) (bool, error) {
+	return lookup(g, name, asBool)
+}
+
+func GetTime(g Getter, name string) (time.Time, error) {
+	return lookup(g, name, asTime)
+}
+
+func GetDecimal(g Getter, name string) (*big.Rat, error) {
+	return lookup(g, name, asDecimal)
+}
+
+func GetUUID(g Getter, name string) (uuid.UUID, error) {
+	return lookup(g, name, asUUID)
+}
+
+// Same as 'crudiator.GetString()', returning "" if the value is missing or cannot be converted
+func (row DbRow) GetString(col string) string {
+	return lenient(GetString(row, col))
+}
+
+// Same as 'crudiator.GetInt64()', returning 0 if the value is missing or cannot be converted
+func (row DbRow) GetInt64(col string) int64 {
+	return lenient(GetInt64(row, col))
+}
+
+// Same as 'crudiator.GetFloat64()', returning 0 if the value is missing or cannot be converted
+func (row DbRow) GetFloat64(col string) float64 {
+	return lenient(GetFloat64(row, col))
+}
+
+// Same as 'crudiator.GetBool()', returning false if the value is missing or cannot be converted
+func (row DbRow) GetBool(col string) bool {
+	return lenient(GetBool(row, col))
+}
+
+// Same as 'crudiator.GetTime()', returning the zero time if the value is missing or cannot be
+// converted
+func (row DbRow) GetTime(col string) time.Time {
+	return lenient(GetTime(row, col))
+}
+
+// Same as 'crudiator.GetDecimal()', returning nil if the value is missing or cannot be converted
+func (row DbRow) GetDecimal(col string) *big.Rat {
+	return lenient(GetDecimal(row, col))
+}
+
+// Same as 'crudiator.GetUUID()', returning uuid.Nil if the value is missing or cannot be converted
+func (row DbRow) GetUUID(col string) uuid.UUID {
+	return lenient(GetUUID(row, col))
+}
+
+// Same as 'crudiator.GetString()', returning "" if the value is missing or cannot be converted
+func (f MapBackedDataForm) GetString(name string) string {
+	return lenient(GetString(f, name))
+}
+
+// Same as 'crudiator.GetInt64()', returning 0 if the value is missing or cannot be converted
+func (f MapBackedDataForm) GetInt64(name string) int64 {
+	return lenient(GetInt64(f, name))
+}
+
+// Same as 'crudiator.GetFloat64()', returning 0 if the value is missing or cannot be converted
+func (f MapBackedDataForm) GetFloat64(name string) float64 {
+	return lenient(GetFloat64(f, name))
+}
+
+// Same as 'crudiator.GetBool()', returning false if the value is missing or cannot be converted
+func (f MapBackedDataForm) GetBool(name string) bool {
+	return lenient(GetBool(f, name))
+}
+
+// Same as 'crudiator.GetTime()', returning the zero time if the value is missing or cannot be
+// converted
+func (f MapBackedDataForm) GetTime(name string) time.Time {
+	return lenient(GetTime(f, name))
+}
+
+// Same as 'crudiator.GetDecimal()', returning nil if the value is missing or cannot be converted
+func (f MapBackedDataForm) GetDecimal(name string) *big.Rat {
+	return lenient(GetDecimal(f, name))
+}
+
+// Same as 'crudiator.GetUUID()', returning uuid.Nil if the value is missing or cannot be converted
+func (f MapBackedDataForm) GetUUID(name string) uuid.UUID {
+	return lenient(GetUUID(f, name))
+}
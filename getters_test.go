@@ -0,0 +1,81 @@
+package crudiator_test
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/SharkFourSix/crudiator"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormGetters(t *testing.T) {
+	id := uuid.New()
+	form := crudiator.MapBackedDataForm{
+		"age":      "25",
+		"score":    json.Number("9.75"),
+		"count":    float64(3),
+		"active":   "on",
+		"dob":      "2001-02-03",
+		"price":    "19.99",
+		"id":       id.String(),
+		"nickname": 42,
+	}
+
+	require.Equal(t, int64(25), form.GetInt64("age"))
+	require.Equal(t, int64(3), form.GetInt64("count"))
+	require.Equal(t, 9.75, form.GetFloat64("score"))
+	require.True(t, form.GetBool("active"))
+	require.Equal(t, time.Date(2001, 2, 3, 0, 0, 0, 0, time.UTC), form.GetTime("dob"))
+	require.Equal(t, 0, form.GetDecimal("price").Cmp(big.NewRat(1999, 100)))
+	require.Equal(t, id, form.GetUUID("id"))
+	require.Equal(t, "42", form.GetString("nickname"))
+
+	// lenient getters return the zero value
+	require.Equal(t, int64(0), form.GetInt64("missing"))
+	require.Equal(t, int64(0), form.GetInt64("active"))
+	require.Nil(t, form.GetDecimal("missing"))
+
+	// forms read by the HTTP adapters are only known as DataForm
+	var data crudiator.DataForm = form
+	age, err := crudiator.GetInt64(data, "age")
+	require.NoError(t, err)
+	require.Equal(t, int64(25), age)
+	_, err = crudiator.GetInt64(data, "missing")
+	require.ErrorIs(t, err, crudiator.ErrNoValue)
+	_, err = crudiator.GetInt64(data, "score")
+	require.Error(t, err)
+	_, err = crudiator.GetUUID(data, "age")
+	require.Error(t, err)
+
+	// 2^63 does not fit into an int64
+	_, err = crudiator.GetInt64(crudiator.MapBackedDataForm{"n": "9223372036854775808"}, "n")
+	require.Error(t, err)
+	_, err = crudiator.GetInt64(crudiator.MapBackedDataForm{"n": float64(1 << 63)}, "n")
+	require.Error(t, err)
+	n, err := crudiator.GetInt64(crudiator.MapBackedDataForm{"n": "-9223372036854775808"}, "n")
+	require.NoError(t, err)
+	require.Equal(t, int64(-9223372036854775808), n)
+}
+
+func TestDbRowGetters(t *testing.T) {
+	now := time.Now()
+	row := crudiator.DbRow{
+		"id":         int64(7),
+		"name":       []byte("Jane"),
+		"active":     int64(1),
+		"created_at": now,
+		"balance":    []byte("10.50"),
+	}
+
+	require.Equal(t, int64(7), row.GetInt64("id"))
+	require.Equal(t, "Jane", row.GetString("name"))
+	require.True(t, row.GetBool("active"))
+	require.Equal(t, now, row.GetTime("created_at"))
+	require.Equal(t, "21/2", row.GetDecimal("balance").String())
+
+	_, err := crudiator.GetTime(row, "name")
+	require.Error(t, err)
+}
@@ -2,6 +2,7 @@ package gofiber
 
 import (
 	"encoding/json"
+	"errors"
 	"strings"
 
 	"github.com/SharkFourSix/crudiator"
@@ -33,3 +34,22 @@ func ReadJson(r *fiber.Ctx) (crudiator.DataForm, error) {
 	}
 	return form, nil
 }
+
+// Returns the HTTP status code matching an error returned by an editor operation: 405 Method
+// Not Allowed for an operation disabled on the editor, 500 Internal Server Error otherwise
+func StatusCode(err error) int {
+	if errors.Is(err, crudiator.ErrOperationNotAllowed) {
+		return fiber.StatusMethodNotAllowed
+	}
+	return fiber.StatusInternalServerError
+}
+
+// Converts an error returned by an editor operation into a *fiber.Error carrying the matching
+// status code and, except for disabled operations, the status text. See StatusCode()
+func Error(err error) error {
+	code := StatusCode(err)
+	if code == fiber.StatusMethodNotAllowed {
+		return fiber.NewError(code, err.Error())
+	}
+	return fiber.NewError(code)
+}
@@ -0,0 +1,24 @@
+package gofiber_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/SharkFourSix/crudiator"
+	"github.com/SharkFourSix/crudiator/integration/gofiber"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestError(t *testing.T) {
+	disabled := &crudiator.OperationNotAllowedError{Operation: crudiator.OpDelete, Table: "students"}
+
+	var fe *fiber.Error
+	require.ErrorAs(t, gofiber.Error(disabled), &fe)
+	require.Equal(t, fiber.StatusMethodNotAllowed, fe.Code)
+	require.Equal(t, disabled.Error(), fe.Message)
+
+	require.ErrorAs(t, gofiber.Error(errors.New(`pq: relation "students" does not exist`)), &fe)
+	require.Equal(t, fiber.StatusInternalServerError, fe.Code)
+	require.Equal(t, "Internal Server Error", fe.Message)
+}
@@ -5,6 +5,7 @@ package nethttp
 
 import (
 	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
 
@@ -39,3 +40,23 @@ func ReadJson(r http.Request) (crudiator.DataForm, error) {
 	}
 	return form, nil
 }
+
+// Returns the HTTP status code matching an error returned by an editor operation: 405 Method
+// Not Allowed for an operation disabled on the editor, 500 Internal Server Error otherwise
+func StatusCode(err error) int {
+	if errors.Is(err, crudiator.ErrOperationNotAllowed) {
+		return http.StatusMethodNotAllowed
+	}
+	return http.StatusInternalServerError
+}
+
+// Replies to the request with the status code matching the error and, except for disabled
+// operations, the status text. See StatusCode()
+func WriteError(w http.ResponseWriter, err error) {
+	code := StatusCode(err)
+	if code == http.StatusMethodNotAllowed {
+		http.Error(w, err.Error(), code)
+		return
+	}
+	http.Error(w, http.StatusText(code), code)
+}
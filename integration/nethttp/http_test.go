@@ -0,0 +1,26 @@
+package nethttp_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/SharkFourSix/crudiator"
+	"github.com/SharkFourSix/crudiator/integration/nethttp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteError(t *testing.T) {
+	disabled := &crudiator.OperationNotAllowedError{Operation: crudiator.OpDelete, Table: "students"}
+
+	w := httptest.NewRecorder()
+	nethttp.WriteError(w, disabled)
+	require.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	require.Equal(t, disabled.Error()+"\n", w.Body.String())
+
+	w = httptest.NewRecorder()
+	nethttp.WriteError(w, errors.New(`pq: relation "students" does not exist`))
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+	require.Equal(t, "Internal Server Error\n", w.Body.String())
+}
@@ -0,0 +1,96 @@
+package crudiator
+
+import "database/sql"
+
+// Number of rows scanned ahead and passed to the post-read callback at a time by a RowIterator
+const DefaultStreamChunkSize = 500
+
+// RowIterator scans the rows returned by 'ReadIter()' in chunks of the editor's stream chunk size.
+// It is not safe for concurrent use.
+type RowIterator struct {
+	editor  Editor
+	rows    *sql.Rows
+	scanner *RowScanner
+	chunk   []DbRow
+	pos     int
+	row     DbRow
+	err     error
+	done    bool
+}
+
+func newRowIterator(e Editor, rows *sql.Rows) (*RowIterator, error) {
+	size := e.streamChunkSize
+	if size <= 0 {
+		size = DefaultStreamChunkSize
+	}
+	scanner, err := e.newRowScanner(rows)
+	if err != nil {
+		return nil, err
+	}
+	return &RowIterator{editor: e, rows: rows, scanner: scanner, chunk: make([]DbRow, 0, size)}, nil
+}
+
+// Advances the iterator to the next row, returning false when there are no more rows or
+// an error occurred. Check 'Err()' once Next returns false.
+func (it *RowIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	if it.pos >= len(it.chunk) {
+		if it.done || !it.fill() {
+			it.row = nil
+			return false
+		}
+	}
+	it.row = it.chunk[it.pos]
+	it.pos++
+	return true
+}
+
+// scans the next chunk of rows and runs the post-read callback over it
+func (it *RowIterator) fill() bool {
+	it.chunk = it.chunk[:0]
+	it.pos = 0
+	for len(it.chunk) < cap(it.chunk) {
+		if !it.rows.Next() {
+			it.done = true
+			if err := it.rows.Err(); err != nil {
+				it.err = err
+			}
+			it.rows.Close()
+			break
+		}
+		row, err := it.scanner.Scan(it.rows)
+		if err != nil {
+			it.err = err
+			it.rows.Close()
+			return false
+		}
+		it.chunk = append(it.chunk, row)
+	}
+	if it.err != nil || len(it.chunk) == 0 {
+		return false
+	}
+	// the callback may modify rows in place or remove their columns, but not the chunk itself
+	it.editor.invokePostActionCallback(it.editor.postRead, it.chunk)
+	return true
+}
+
+// Returns the current row. Only valid after a call to 'Next()' returned true.
+func (it *RowIterator) Row() DbRow {
+	return it.row
+}
+
+// Returns the error, if any, that was encountered during iteration
+func (it *RowIterator) Err() error {
+	return it.err
+}
+
+// Closes the underlying result set. It is safe to call Close multiple times and after
+// iteration has completed.
+func (it *RowIterator) Close() error {
+	it.done = true
+	it.chunk = it.chunk[:0]
+	it.pos = 0
+	return it.rows.Close()
+}
@@ -0,0 +1,49 @@
+package crudiator_test
+
+import (
+	"database/sql/driver"
+	"testing"
+
+	"github.com/SharkFourSix/crudiator"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadIter(t *testing.T) {
+	db, fdb := newFakeDb(t)
+
+	var rows [][]driver.Value
+	for i := 1; i <= 5; i++ {
+		rows = append(rows, []driver.Value{int64(i), "secret"})
+	}
+	fdb.queueRows([]string{"id", "password"}, rows...)
+
+	var chunks []int
+	editor := crudiator.MustNewEditor(
+		"users",
+		crudiator.SQLITE,
+		crudiator.NewField("id", crudiator.IsPrimaryKey, crudiator.IncludeOnRead),
+		crudiator.NewField("password", crudiator.IncludeOnRead),
+	).StreamChunkSize(2).
+		OnPostRead(func(editor crudiator.Editor, rows []crudiator.DbRow) {
+			chunks = append(chunks, len(rows))
+			for _, row := range rows {
+				row.Remove("password")
+			}
+		}).
+		Build()
+
+	it, err := editor.ReadIter(crudiator.MapBackedDataForm{}, db)
+	require.NoError(t, err)
+	defer it.Close()
+
+	var ids []any
+	for it.Next() {
+		require.False(t, it.Row().Has("password"))
+		ids = append(ids, it.Row().Get("id"))
+	}
+	require.NoError(t, it.Err())
+	require.Equal(t, []any{int64(1), int64(2), int64(3), int64(4), int64(5)}, ids)
+	require.Equal(t, []int{2, 2, 1}, chunks)
+	require.False(t, it.Next())
+	require.NoError(t, it.Close())
+}
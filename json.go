@@ -0,0 +1,142 @@
+package crudiator
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// Declares a JSON column, i.e. JSONB on PostgreSQL. Values are encoded on write, unless they
+	// already are JSON text, and decoded on read: objects into map[string]any, arrays into []any.
+	IsJSON FieldOption = func(f *Field) {
+		f.Type = JsonField
+		f.TypeDeclared = true
+		f.DecodeJSON = true
+	}
+
+	// Makes the field a selection filter on the value found at the path of keys in the JSON
+	// column, which the form carries under the name of the field:
+	//
+	//	NewField("grade", FromJSONPath("metadata", "grade"))
+	//
+	//	// PostgreSQL: "metadata"->>'grade'=$1
+	//	// MySQL: JSON_UNQUOTE(JSON_EXTRACT(`metadata`,'$.grade'))=?
+	//	// SQLite: json_extract(`metadata`,'$.grade')=?
+	//
+	// The field does not match a column, so it can neither be read nor written. On PostgreSQL,
+	// the value at the path is compared as text. On MySQL and SQLite, the keys cannot contain '"'
+	// or '\', which their JSON paths cannot reliably escape.
+	FromJSONPath = func(column string, keys ...string) FieldOption {
+		return func(f *Field) {
+			f.SelectionFilter = true
+			f.JSONColumn = column
+			f.JSONPath = keys
+		}
+	}
+)
+
+var jsonPathIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Returns the SQL expression of the value found at the path in the JSON column expression
+func (e Editor) jsonPathExpression(column string, path []string) string {
+	if e.dialect == POSTGRESQL {
+		var builder strings.Builder
+		builder.WriteString(column)
+		for i, key := range path {
+			if i == len(path)-1 {
+				builder.WriteString("->>")
+			} else {
+				builder.WriteString("->")
+			}
+			builder.WriteString(sqlLiteral(key))
+		}
+		return builder.String()
+	}
+
+	var jsonPath strings.Builder
+	jsonPath.WriteRune('$')
+	for _, key := range path {
+		jsonPath.WriteRune('.')
+		if jsonPathIdentifier.MatchString(key) {
+			jsonPath.WriteString(key)
+		} else {
+			jsonPath.WriteString(`"` + key + `"`)
+		}
+	}
+	if e.dialect == MYSQL {
+		return "JSON_UNQUOTE(JSON_EXTRACT(" + column + "," + sqlLiteral(jsonPath.String()) + "))"
+	}
+	return "json_extract(" + column + "," + sqlLiteral(jsonPath.String()) + ")"
+}
+
+// Returns the expression of the field in the conditions of statements, optionally qualified
+// with the name of the table
+func (e Editor) fieldExpression(f Field, qualified bool) string {
+	column := f.Name
+	if f.JSONColumn != "" {
+		column = f.JSONColumn
+	}
+	if qualified {
+		column = e.qualify(column)
+	} else {
+		column = e.quote(column)
+	}
+	if f.JSONColumn != "" {
+		return e.jsonPathExpression(column, f.JSONPath)
+	}
+	return column
+}
+
+func (e *Editor) checkJSONPaths() *Editor {
+	for _, f := range e.fields {
+		if f.JSONColumn == "" {
+			continue
+		}
+		if len(f.JSONPath) == 0 {
+			panic(errors.Errorf("field '%s' has an empty JSON path", f.Name))
+		}
+		if f.Create || f.Read || f.Update || f.PrimaryKey {
+			panic(errors.Errorf("JSON path field '%s' can only be used as a selection filter", f.Name))
+		}
+		if e.dialect == POSTGRESQL {
+			continue
+		}
+		for _, key := range f.JSONPath {
+			if strings.ContainsAny(key, `"\`) {
+				panic(errors.Errorf("JSON path field '%s' has a key with a quote or a backslash", f.Name))
+			}
+		}
+	}
+	return e
+}
+
+// Decodes JSON text read from the database
+func decodeJSON(value any) (any, error) {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case json.RawMessage:
+		data = v
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		// already decoded by the driver
+		return value, nil
+	}
+	var decoded any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, errors.Wrap(err, "invalid json")
+	}
+	return decoded, nil
+}
+
+// Returns the SQL string literal of the text
+func sqlLiteral(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -0,0 +1,76 @@
+package crudiator_test
+
+import (
+	"database/sql/driver"
+	"testing"
+
+	"github.com/SharkFourSix/crudiator"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	metadataField = crudiator.NewField("metadata", crudiator.IncludeAlways, crudiator.IsJSON)
+	gradeFilter   = crudiator.NewField("grade", crudiator.FromJSONPath("metadata", "grade"))
+	cityFilter    = crudiator.NewField("city", crudiator.FromJSONPath("metadata", "address", "home city"))
+)
+
+func TestJSONField(t *testing.T) {
+	db, fdb := newFakeDb(t)
+	editor := newStudentEditor(crudiator.POSTGRESQL, metadataField, gradeFilter, cityFilter).Build()
+
+	fdb.queueRows([]string{"id", "metadata"}, []driver.Value{int64(1), []byte(`{"grade":"A","tags":["x"]}`)})
+	row, err := editor.Create(crudiator.MapBackedDataForm{"name": "Jane", "metadata": map[string]any{"grade": "A", "tags": []string{"x"}}}, db)
+	require.NoError(t, err)
+	require.Equal(t, []any{"Jane", `{"grade":"A","tags":["x"]}`}, fdb.last().Args)
+	require.Equal(t, map[string]any{"grade": "A", "tags": []any{"x"}}, row.Get("metadata"))
+
+	fdb.queueRows([]string{"id", "metadata"}, []driver.Value{int64(1), nil})
+	rows, err := editor.Read(crudiator.MapBackedDataForm{"grade": "A", "city": "Blantyre"}, db)
+	require.NoError(t, err)
+	require.Equal(t, `SELECT "id","name","metadata" FROM "students" WHERE ("metadata"->>'grade'=$1 AND "metadata"->'address'->>'home city'=$2)`, fdb.last().Query)
+	require.Equal(t, []any{"A", "Blantyre"}, fdb.last().Args)
+	require.Nil(t, rows[0].Get("metadata"))
+}
+
+func TestJSONPathDialects(t *testing.T) {
+	db, fdb := newFakeDb(t)
+
+	fdb.queueRows([]string{"count"}, []driver.Value{int64(0)})
+	_, err := newStudentEditor(crudiator.MYSQL, metadataField, gradeFilter, cityFilter).Build().Count(crudiator.MapBackedDataForm{"grade": "A", "city": "Zomba"}, db)
+	require.NoError(t, err)
+	require.Equal(t, "SELECT COUNT(*) FROM `students` WHERE (JSON_UNQUOTE(JSON_EXTRACT(`metadata`,'$.grade'))=? AND JSON_UNQUOTE(JSON_EXTRACT(`metadata`,'$.address.\"home city\"'))=?)", fdb.last().Query)
+
+	fdb.queueRows([]string{"id", "metadata"})
+	_, err = newStudentEditor(crudiator.SQLITE, metadataField, gradeFilter, cityFilter).Build().Read(crudiator.MapBackedDataForm{"grade": "A", "city": "Zomba"}, db)
+	require.NoError(t, err)
+	require.Equal(t, "SELECT `id`,`name`,`metadata` FROM `students` WHERE (json_extract(`metadata`,'$.grade')=? AND json_extract(`metadata`,'$.address.\"home city\"')=?)", fdb.last().Query)
+
+	require.Panics(t, func() {
+		newStudentEditor(crudiator.SQLITE, crudiator.NewField("grade", crudiator.IncludeAlways, crudiator.FromJSONPath("metadata", "grade"))).Build()
+	})
+}
+
+func TestJSONPathKeys(t *testing.T) {
+	db, fdb := newFakeDb(t)
+	newEditor := func(dialect crudiator.SQLDialect, key string) crudiator.Crudiator {
+		return newStudentEditor(dialect, crudiator.NewField("nick", crudiator.FromJSONPath("metadata", key))).Build()
+	}
+
+	// question marks within the path are not placeholders
+	fdb.queueRows([]string{"count"}, []driver.Value{int64(0)})
+	_, err := newEditor(crudiator.MYSQL, "nick?").Count(crudiator.MapBackedDataForm{"nick": "Jo"}, db)
+	require.NoError(t, err)
+	require.Equal(t, "SELECT COUNT(*) FROM `students` WHERE (JSON_UNQUOTE(JSON_EXTRACT(`metadata`,'$.\"nick?\"'))=?)", fdb.last().Query)
+	require.Equal(t, []any{"Jo"}, fdb.last().Args)
+
+	fdb.queueRows([]string{"count"}, []driver.Value{int64(0)})
+	_, err = newEditor(crudiator.POSTGRESQL, `it's "nick" \`).Count(crudiator.MapBackedDataForm{"nick": "Jo"}, db)
+	require.NoError(t, err)
+	require.Equal(t, `SELECT COUNT(*) FROM "students" WHERE ("metadata"->>'it''s "nick" \'=$1)`, fdb.last().Query)
+
+	for _, dialect := range []crudiator.SQLDialect{crudiator.MYSQL, crudiator.SQLITE} {
+		for _, key := range []string{`say "nick"`, `back\slash`} {
+			require.Panics(t, func() { newEditor(dialect, key) }, key)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package crudiator
+
+import (
+	"context"
+	"database/sql"
+)
+
+// LockMode is the row locking clause appended to the selection statements of
+// 'SingleReadForUpdate()' and 'ReadForUpdate()'
+type LockMode int
+
+const (
+	// Locks the selected rows against concurrent updates, deletes and locks. FOR UPDATE
+	ForUpdate LockMode = iota + 1
+	// Same as ForUpdate but skips the rows already locked by other transactions instead of
+	// waiting for them, which allows several consumers to pick jobs from the same table.
+	// FOR UPDATE SKIP LOCKED
+	ForUpdateSkipLocked
+	// Locks the selected rows against concurrent updates and deletes while letting other
+	// transactions read and share-lock them. FOR SHARE
+	ForShare
+)
+
+// Returns the locking clause of the mode, which is empty on SQLite since it has no row locks
+func (m LockMode) clause(dialect SQLDialect) string {
+	if dialect == SQLITE {
+		return ""
+	}
+	switch m {
+	case ForUpdate:
+		return " FOR UPDATE"
+	case ForUpdateSkipLocked:
+		return " FOR UPDATE SKIP LOCKED"
+	case ForShare:
+		return " FOR SHARE"
+	}
+	return ""
+}
+
+// Returns the selection statement with the locking clause of the mode appended
+func (s statement) locking(m LockMode, dialect SQLDialect) statement {
+	s.sql += m.clause(dialect)
+	return s
+}
+
+// Executes statements. Satisfied by *sql.DB, *sql.Tx and *sql.Conn
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// Runs fn in a transaction, which is committed if fn succeeds and rolled back otherwise
+func (e Editor) inTransaction(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// SingleReadForUpdate reads a single row like 'SingleRead()' and locks it until the transaction
+// ends. On SQLite, the lock mode is ignored.
+func (e Editor) SingleReadForUpdate(form DataForm, tx *sql.Tx, lock LockMode) (DbRow, error) {
+	return e.SingleReadForUpdateContext(context.Background(), form, tx, lock)
+}
+
+func (e Editor) SingleReadForUpdateContext(ctx context.Context, form DataForm, tx *sql.Tx, lock LockMode) (DbRow, error) {
+	e = e.forRole(ctx)
+	if err := e.checkOperation(OpRead); err != nil {
+		return nil, err
+	}
+	predicates, err := e.authorize(ctx, OpRead, form)
+	if err != nil {
+		return nil, err
+	}
+	return e.singleReadWith(ctx, tx, e.singleSelectionStatement.locking(lock, e.dialect), form, predicates)
+}
+
+// ReadForUpdate reads rows like 'Read()' and locks them until the transaction ends. On SQLite,
+// the lock mode is ignored.
+func (e Editor) ReadForUpdate(form DataForm, tx *sql.Tx, lock LockMode, pageable ...Pageable) ([]DbRow, error) {
+	return e.ReadForUpdateContext(context.Background(), form, tx, lock, pageable...)
+}
+
+func (e Editor) ReadForUpdateContext(ctx context.Context, form DataForm, tx *sql.Tx, lock LockMode, pageable ...Pageable) ([]DbRow, error) {
+	e = e.forRole(ctx)
+	if err := e.checkOperation(OpRead); err != nil {
+		return nil, err
+	}
+	e.invokePreActionCallback(e.preRead, form)
+	predicates, err := e.authorize(ctx, OpRead, form)
+	if err != nil {
+		return nil, err
+	}
+	results, err := e.readWith(ctx, tx, e.readStatement.locking(lock, e.dialect), form, predicates, pageable...)
+	if err != nil {
+		return nil, err
+	}
+	e.invokePostActionCallback(e.postRead, results)
+	return results, nil
+}
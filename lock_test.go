@@ -0,0 +1,73 @@
+package crudiator_test
+
+import (
+	"database/sql/driver"
+	"strings"
+	"testing"
+
+	"github.com/SharkFourSix/crudiator"
+	"github.com/stretchr/testify/require"
+)
+
+var statusFilter = crudiator.NewField("status", crudiator.IncludeAlways, crudiator.IsSelectionFilter)
+
+func TestReadForUpdate(t *testing.T) {
+	db, fdb := newFakeDb(t)
+	cases := []struct {
+		dialect crudiator.SQLDialect
+		lock    crudiator.LockMode
+		suffix  string
+	}{
+		{crudiator.POSTGRESQL, crudiator.ForUpdateSkipLocked, "LIMIT $3 FOR UPDATE SKIP LOCKED"},
+		{crudiator.MYSQL, crudiator.ForShare, "LIMIT ? FOR SHARE"},
+		{crudiator.SQLITE, crudiator.ForUpdate, "LIMIT ?"},
+	}
+	for _, c := range cases {
+		editor := newStudentEditor(c.dialect, statusFilter).MustPaginate(crudiator.KEYSET, "id").Build()
+		tx, err := db.Begin()
+		require.NoError(t, err)
+
+		fdb.queueRows([]string{"id", "name", "status"}, []driver.Value{int64(1), "Jane", "pending"})
+		rows, err := editor.ReadForUpdate(crudiator.MapBackedDataForm{"status": "pending"}, tx, c.lock, crudiator.NewKeysetPaging(0, 10))
+		require.NoError(t, err)
+		require.Len(t, rows, 1)
+		require.True(t, strings.HasSuffix(fdb.last().Query, c.suffix), fdb.last().Query)
+		require.NoError(t, tx.Commit())
+	}
+}
+
+func TestSingleReadForUpdate(t *testing.T) {
+	db, fdb := newFakeDb(t)
+	editor := newStudentEditor(crudiator.POSTGRESQL, statusFilter).MustPaginate(crudiator.KEYSET, "id").Build()
+	tx, err := db.Begin()
+	require.NoError(t, err)
+	defer tx.Rollback()
+
+	fdb.queueRows([]string{"id", "name", "status"}, []driver.Value{int64(1), "Jane", "pending"})
+	row, err := editor.SingleReadForUpdate(crudiator.MapBackedDataForm{"id": 1, "status": "pending"}, tx, crudiator.ForUpdate)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), row["id"])
+	require.Equal(t, `SELECT "id","name","status" FROM "students" WHERE ("id"=$1) AND ("status"=$2) FOR UPDATE`, fdb.last().Query)
+}
+
+func TestSingleReadForUpdateCallbacks(t *testing.T) {
+	db, fdb := newFakeDb(t)
+	var callbacks int
+	editor := newStudentEditor(crudiator.POSTGRESQL).OnPreRead(func(editor crudiator.Editor, form crudiator.DataForm) {
+		callbacks++
+	}).OnPostRead(func(editor crudiator.Editor, rows []crudiator.DbRow) {
+		callbacks++
+	}).Build()
+	tx, err := db.Begin()
+	require.NoError(t, err)
+	defer tx.Rollback()
+
+	// like SingleRead, the read callbacks are not invoked
+	fdb.queueRows([]string{"id", "name", "status"}, []driver.Value{int64(1), "Jane", "pending"})
+	_, err = editor.SingleReadForUpdate(crudiator.MapBackedDataForm{"id": 1}, tx, crudiator.ForUpdate)
+	require.NoError(t, err)
+	fdb.queueRows([]string{"id", "name", "status"}, []driver.Value{int64(1), "Jane", "pending"})
+	_, err = editor.SingleRead(crudiator.MapBackedDataForm{"id": 1}, db)
+	require.NoError(t, err)
+	require.Zero(t, callbacks)
+}
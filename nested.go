@@ -0,0 +1,130 @@
+package crudiator
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+// CreateNested creates the row and, through the related editors, the rows nested in the form under
+// the name of a 'HasMany()' relation, in a single transaction.
+func (e Editor) CreateNested(form DataForm, db *sql.DB) (DbRow, error) {
+	return e.CreateNestedContext(context.Background(), form, db)
+}
+
+func (e Editor) CreateNestedContext(ctx context.Context, form DataForm, db *sql.DB) (DbRow, error) {
+	var row DbRow
+	var created []func()
+	err := e.inTransaction(ctx, db, func(tx *sql.Tx) (err error) {
+		row, err = e.createNested(ctx, form, tx, &created)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, callback := range created {
+		callback()
+	}
+	return row, nil
+}
+
+// Creates the row and its children, adding the post-create callbacks of the created rows to
+// created
+func (e Editor) createNested(ctx context.Context, form DataForm, q queryer, created *[]func()) (DbRow, error) {
+	e = e.forRole(ctx)
+	if err := e.checkOperation(OpCreate); err != nil {
+		return nil, err
+	}
+
+	var nested []relation
+	children := make(map[string][]DataForm)
+	for _, r := range e.relations {
+		if r.kind != HasManyRelation || !form.Has(r.name) {
+			continue
+		}
+		forms, err := childForms(r.name, form.Get(r.name))
+		if err != nil {
+			return nil, err
+		}
+		nested = append(nested, r)
+		children[r.name] = forms
+	}
+
+	if len(nested) > 0 {
+		// leave the children out of the form of the row
+		parent := MapBackedDataForm{}
+		form.Iterate(func(key string, value any) {
+			if _, ok := children[key]; !ok {
+				parent[key] = value
+			}
+		})
+		form = parent
+	}
+
+	row, err := e.create(ctx, form, q)
+	if err != nil {
+		return nil, err
+	}
+	*created = append(*created, func() { e.invokePostActionCallback(e.postCreate, []DbRow{row}) })
+
+	for _, r := range nested {
+		key := row.Get(e.primaryKeyField)
+		if key == nil {
+			return nil, errors.Errorf("field '%s' of the created row is not read", e.primaryKeyField)
+		}
+		rows := make([]DbRow, 0, len(children[r.name]))
+		for i, child := range children[r.name] {
+			child.Set(r.foreignKey, key)
+			childRow, err := r.editor.createNested(ctx, child, q, created)
+			if err != nil {
+				return nil, errors.Wrapf(err, "%s[%d]", r.name, i)
+			}
+			rows = append(rows, childRow)
+		}
+		row[r.name] = rows
+	}
+	return row, nil
+}
+
+// Returns copies of the child forms nested in a form
+func childForms(name string, value any) ([]DataForm, error) {
+	var items []any
+	switch list := value.(type) {
+	case nil:
+		return nil, nil
+	case []any:
+		items = list
+	case []DataForm:
+		for _, f := range list {
+			items = append(items, f)
+		}
+	case []MapBackedDataForm:
+		for _, f := range list {
+			items = append(items, f)
+		}
+	case []map[string]any:
+		for _, f := range list {
+			items = append(items, f)
+		}
+	default:
+		return nil, ValidationErrors{name: {"must be a list of objects"}}
+	}
+
+	forms := make([]DataForm, 0, len(items))
+	for _, item := range items {
+		form := MapBackedDataForm{}
+		switch v := item.(type) {
+		case DataForm:
+			v.Iterate(func(key string, value any) { form[key] = value })
+		case map[string]any:
+			for key, value := range v {
+				form[key] = value
+			}
+		default:
+			return nil, ValidationErrors{name: {"must be a list of objects"}}
+		}
+		forms = append(forms, form)
+	}
+	return forms, nil
+}
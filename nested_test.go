@@ -0,0 +1,93 @@
+package crudiator_test
+
+import (
+	"database/sql/driver"
+	"errors"
+	"testing"
+
+	"github.com/SharkFourSix/crudiator"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateNested(t *testing.T) {
+	db, fdb := newFakeDb(t)
+	schools, students, _ := newSchoolEditors()
+	var created []crudiator.DbRow
+	students.OnPostCreate(func(editor crudiator.Editor, rows []crudiator.DbRow) {
+		created = append(created, rows...)
+	})
+
+	fdb.queueRows([]string{"id", "name"}, []driver.Value{int64(1), "Chichiri"})
+	fdb.queueRows([]string{"id", "name", "school_id"}, []driver.Value{int64(10), "Jane", int64(1)})
+	fdb.queueRows([]string{"id", "name", "school_id"}, []driver.Value{int64(11), "John", int64(1)})
+	row, err := schools.CreateNested(crudiator.MapBackedDataForm{
+		"name":     "Chichiri",
+		"students": []any{map[string]any{"name": "Jane"}, map[string]any{"name": "John"}},
+	}, db)
+	require.NoError(t, err)
+
+	queries := fdb.all()
+	require.Len(t, queries, 3)
+	require.Equal(t, `INSERT INTO "schools"("name") VALUES ($1) RETURNING "id","name"`, queries[0].Query)
+	require.Equal(t, `INSERT INTO "students"("name","school_id") VALUES ($1,$2) RETURNING "id","name","school_id"`, queries[1].Query)
+	require.Equal(t, []any{"Jane", int64(1)}, queries[1].Args)
+	require.Equal(t, []any{"John", int64(1)}, queries[2].Args)
+	require.Equal(t, []string{"commit"}, fdb.txEnds)
+
+	require.Len(t, row.Get("students"), 2)
+	require.Len(t, created, 2)
+}
+
+func TestCreateNestedRollback(t *testing.T) {
+	db, fdb := newFakeDb(t)
+	schools, _, _ := newSchoolEditors()
+
+	_, err := schools.CreateNested(crudiator.MapBackedDataForm{"name": "Chichiri", "students": "Jane"}, db)
+	var validation crudiator.ValidationErrors
+	require.ErrorAs(t, err, &validation)
+	require.Empty(t, fdb.all())
+
+	fdb.queueRows([]string{"id", "name"}, []driver.Value{int64(1), "Chichiri"})
+	fdb.queue(fakeResult{Err: errors.New("constraint violation")})
+	_, err = schools.CreateNested(crudiator.MapBackedDataForm{
+		"name":     "Chichiri",
+		"students": []crudiator.MapBackedDataForm{{"name": "Jane"}},
+	}, db)
+	require.Error(t, err)
+	require.Equal(t, []string{"rollback", "rollback"}, fdb.txEnds)
+}
+
+func TestCreateNestedCallbacksAfterCommit(t *testing.T) {
+	db, fdb := newFakeDb(t)
+	schools, students, _ := newSchoolEditors()
+	var created []string
+	schools.OnPostCreate(func(editor crudiator.Editor, rows []crudiator.DbRow) {
+		created = append(created, "school")
+	})
+	students.OnPostCreate(func(editor crudiator.Editor, rows []crudiator.DbRow) {
+		created = append(created, rows[0].GetString("name"))
+	})
+	form := func() crudiator.MapBackedDataForm {
+		return crudiator.MapBackedDataForm{
+			"name":     "Chichiri",
+			"students": []any{map[string]any{"name": "Jane"}, map[string]any{"name": "John"}},
+		}
+	}
+
+	// the second child fails, so none of the rows created before it exist
+	fdb.queueRows([]string{"id", "name"}, []driver.Value{int64(1), "Chichiri"})
+	fdb.queueRows([]string{"id", "name", "school_id"}, []driver.Value{int64(10), "Jane", int64(1)})
+	fdb.queue(fakeResult{Err: errors.New("constraint violation")})
+	_, err := schools.CreateNested(form(), db)
+	require.ErrorContains(t, err, "students[1]")
+	require.Equal(t, []string{"rollback"}, fdb.txEnds)
+	require.Empty(t, created)
+
+	fdb.queueRows([]string{"id", "name"}, []driver.Value{int64(1), "Chichiri"})
+	fdb.queueRows([]string{"id", "name", "school_id"}, []driver.Value{int64(10), "Jane", int64(1)})
+	fdb.queueRows([]string{"id", "name", "school_id"}, []driver.Value{int64(11), "John", int64(1)})
+	_, err = schools.CreateNested(form(), db)
+	require.NoError(t, err)
+	require.Equal(t, []string{"rollback", "commit"}, fdb.txEnds)
+	require.Equal(t, []string{"school", "Jane", "John"}, created)
+}
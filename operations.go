@@ -0,0 +1,67 @@
+package crudiator
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// Matches, through errors.Is(), the errors returned by the operations disabled on an editor
+var ErrOperationNotAllowed = errors.New("operation not allowed")
+
+// OperationNotAllowedError is returned by the operations disabled on an editor
+type OperationNotAllowedError struct {
+	Operation Operation
+	Table     string
+}
+
+func (e *OperationNotAllowedError) Error() string {
+	return fmt.Sprintf("%s not allowed on %s", e.Operation, e.Table)
+}
+
+func (e *OperationNotAllowedError) Is(target error) bool {
+	return target == ErrOperationNotAllowed
+}
+
+// AllowOperations enables the given operations and disables all others, which then fail with an
+// *OperationNotAllowedError. All operations are enabled by default.
+func (e *Editor) AllowOperations(ops ...Operation) *Editor {
+	e.disabledOperations = map[Operation]bool{
+		OpCreate: true,
+		OpRead:   true,
+		OpUpdate: true,
+		OpDelete: true,
+	}
+	for _, op := range ops {
+		delete(e.disabledOperations, op)
+	}
+	return e
+}
+
+// DisallowOperations disables the given operations. See 'AllowOperations()'
+func (e *Editor) DisallowOperations(ops ...Operation) *Editor {
+	if e.disabledOperations == nil {
+		e.disabledOperations = make(map[Operation]bool)
+	}
+	for _, op := range ops {
+		e.disabledOperations[op] = true
+	}
+	return e
+}
+
+// ReadOnly disables create, update and delete, i.e. for lookup tables and views
+func (e *Editor) ReadOnly() *Editor {
+	return e.AllowOperations(OpRead)
+}
+
+// Returns whether the operation is enabled on the editor
+func (e Editor) Allows(op Operation) bool {
+	return !e.disabledOperations[op]
+}
+
+func (e Editor) checkOperation(op Operation) error {
+	if e.disabledOperations[op] {
+		return &OperationNotAllowedError{Operation: op, Table: e.tableName}
+	}
+	return nil
+}
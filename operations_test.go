@@ -0,0 +1,58 @@
+package crudiator_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/SharkFourSix/crudiator"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadOnlyEditor(t *testing.T) {
+	db, fdb := newFakeDb(t)
+	editor := crudiator.MustNewEditor(
+		"countries",
+		crudiator.POSTGRESQL,
+		crudiator.NewField("code", crudiator.IsPrimaryKey, crudiator.IncludeAlways),
+		crudiator.NewField("name", crudiator.IncludeAlways),
+	).ReadOnly().Build()
+
+	require.True(t, editor.Allows(crudiator.OpRead))
+	require.False(t, editor.Allows(crudiator.OpDelete))
+
+	form := crudiator.MapBackedDataForm{"code": "MW", "name": "Malawi"}
+	_, err := editor.Create(form, db)
+	require.ErrorIs(t, err, crudiator.ErrOperationNotAllowed)
+	_, err = editor.Update(form, db)
+	require.ErrorIs(t, err, crudiator.ErrOperationNotAllowed)
+	_, err = editor.Delete(form, db)
+	require.ErrorIs(t, err, crudiator.ErrOperationNotAllowed)
+
+	var notAllowed *crudiator.OperationNotAllowedError
+	require.True(t, errors.As(err, &notAllowed))
+	require.Equal(t, crudiator.OpDelete, notAllowed.Operation)
+	require.Equal(t, "delete not allowed on countries", err.Error())
+	require.Empty(t, fdb.all())
+
+	fdb.queueRows([]string{"code", "name"})
+	_, err = editor.Read(crudiator.MapBackedDataForm{}, db)
+	require.NoError(t, err)
+}
+
+func TestDisallowOperations(t *testing.T) {
+	db, fdb := newFakeDb(t)
+	editor := crudiator.MustNewEditor(
+		"audit_log",
+		crudiator.SQLITE,
+		crudiator.NewField("id", crudiator.IsPrimaryKey, crudiator.IncludeOnRead),
+		crudiator.NewField("message", crudiator.IncludeAlways),
+	).DisallowOperations(crudiator.OpUpdate, crudiator.OpDelete).Build()
+
+	fdb.queue(fakeResult{RowsAffected: 1, LastInsertId: 1})
+	fdb.queueRows([]string{"id", "message"})
+	_, err := editor.Create(crudiator.MapBackedDataForm{"message": "created"}, db)
+	require.NoError(t, err)
+
+	_, err = editor.Delete(crudiator.MapBackedDataForm{"id": 1}, db)
+	require.ErrorIs(t, err, crudiator.ErrOperationNotAllowed)
+}
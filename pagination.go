@@ -1,5 +1,20 @@
 package crudiator
 
+import "github.com/pkg/errors"
+
+// Returned by 'ReadPage()' when the editor has not been configured through 'MustPaginate()'
+var ErrPaginationNotConfigured = errors.New("pagination has not been configured")
+
+// Returned by 'ReadPage()' when it is given a nil Pageable
+var ErrNoPageable = errors.New("no pageable")
+
+// Returned by 'ReadPage()' when the page size is not positive
+var ErrInvalidPageSize = errors.New("page size must be positive")
+
+// Returned by 'ReadPage()' on keyset editors whose keyset field is not returned, from which the
+// next page could not be derived
+var ErrKeysetFieldNotRead = errors.New("keyset field is not read")
+
 // Defines how to query a table. i.e select everything at once or paginate.
 //
 // Default is NONE
@@ -51,6 +66,14 @@ func (of OffsetPaging) KeysetValue() any {
 	return nil
 }
 
+// Returns the zero based page number derived from the offset and page size
+func (of OffsetPaging) Number() int {
+	if of.PageSize <= 0 {
+		return 0
+	}
+	return of.PageOffset / of.PageSize
+}
+
 func NewOffsetPaging(page, size int) Pageable {
 	return &OffsetPaging{PageOffset: page * size, PageSize: size}
 }
@@ -58,6 +81,9 @@ func NewOffsetPaging(page, size int) Pageable {
 type KeysetPaging struct {
 	Value    any
 	PageSize int
+	// Zero based page number. Keyset pagination cannot derive the page number by itself, so
+	// it is carried along by 'Page.Next()'.
+	PageNumber int
 }
 
 func (kp KeysetPaging) Offset() int {
@@ -72,6 +98,41 @@ func (kp KeysetPaging) KeysetValue() any {
 	return kp.Value
 }
 
+// Returns the zero based page number
+func (kp KeysetPaging) Number() int {
+	return kp.PageNumber
+}
+
 func NewKeysetPaging(value any, size int) Pageable {
 	return &KeysetPaging{Value: value, PageSize: size}
 }
+
+// Page holds the rows of a single page returned by 'ReadPage()' along with the metadata
+// required to render pagination controls.
+type Page struct {
+	Rows []DbRow `json:"rows"`
+	// Total number of rows matching the selection filters, or -1 if the total was not requested
+	Total int64 `json:"total"`
+	// Indicates whether there are more rows after this page
+	HasMore bool `json:"hasMore"`
+	// Zero based page number
+	Number int `json:"page"`
+	// Requested page size
+	Size int `json:"size"`
+	next Pageable
+}
+
+// Returns the Pageable for the page following this one, or nil if there are no more rows
+func (p Page) Next() Pageable {
+	return p.next
+}
+
+func pageNumber(p Pageable) int {
+	if n, ok := p.(interface{ Number() int }); ok {
+		return n.Number()
+	}
+	if p.Size() > 0 {
+		return p.Offset() / p.Size()
+	}
+	return 0
+}
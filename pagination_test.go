@@ -0,0 +1,119 @@
+package crudiator_test
+
+import (
+	"database/sql/driver"
+	"testing"
+
+	"github.com/SharkFourSix/crudiator"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadPageKeyset(t *testing.T) {
+	db, fdb := newFakeDb(t)
+	editor := newStudentEditor(crudiator.POSTGRESQL, deletedAtFilter, schoolFilter).MustPaginate(crudiator.KEYSET, "id").Build()
+
+	cols := []string{"id", "name", "deleted_at", "school_id"}
+	fdb.queueRows(cols,
+		[]driver.Value{int64(1), "a", nil, int64(1)},
+		[]driver.Value{int64(2), "b", nil, int64(1)},
+		[]driver.Value{int64(3), "c", nil, int64(1)},
+	).queueRows([]string{"count"}, []driver.Value{int64(42)})
+
+	form := crudiator.MapBackedDataForm{"school_id": 1}
+	page, err := editor.ReadPage(form, db, crudiator.NewKeysetPaging(0, 2), true)
+	require.NoError(t, err)
+
+	queries := fdb.all()
+	require.Len(t, queries, 2)
+	require.Equal(t, `SELECT "id","name","deleted_at","school_id" FROM "students" WHERE ("deleted_at" IS NULL AND "school_id"=$1) AND ("id">$2) ORDER BY "id" ASC LIMIT $3`, queries[0].Query)
+	require.Equal(t, []any{1, 0, 3}, queries[0].Args)
+	require.Equal(t, `SELECT COUNT(*) FROM "students" WHERE ("deleted_at" IS NULL AND "school_id"=$1)`, queries[1].Query)
+
+	require.Len(t, page.Rows, 2)
+	require.True(t, page.HasMore)
+	require.Equal(t, int64(42), page.Total)
+	require.Equal(t, 0, page.Number)
+	require.Equal(t, 2, page.Size)
+
+	next := page.Next()
+	require.NotNil(t, next)
+	require.Equal(t, int64(2), next.KeysetValue())
+	require.Equal(t, 2, next.Size())
+}
+
+func TestReadPageOffsetLastPage(t *testing.T) {
+	db, fdb := newFakeDb(t)
+	editor := newStudentEditor(crudiator.POSTGRESQL, deletedAtFilter, schoolFilter).MustPaginate(crudiator.OFFSET, "id").Build()
+
+	fdb.queueRows([]string{"id", "name", "deleted_at", "school_id"},
+		[]driver.Value{int64(5), "e", nil, int64(1)},
+	)
+
+	form := crudiator.MapBackedDataForm{"school_id": 1}
+	page, err := editor.ReadPage(form, db, crudiator.NewOffsetPaging(2, 2), false)
+	require.NoError(t, err)
+
+	require.Len(t, fdb.all(), 1)
+	require.Equal(t, []any{1, 4, 3}, fdb.last().Args)
+	require.Len(t, page.Rows, 1)
+	require.False(t, page.HasMore)
+	require.Equal(t, int64(-1), page.Total)
+	require.Equal(t, 2, page.Number)
+	require.Nil(t, page.Next())
+}
+
+func TestReadOffsetBindsOffsetFirst(t *testing.T) {
+	db, fdb := newFakeDb(t)
+	for _, dialect := range []crudiator.SQLDialect{crudiator.MYSQL, crudiator.SQLITE} {
+		editor := crudiator.MustNewEditor(
+			"students",
+			dialect,
+			crudiator.NewField("id", crudiator.IsPrimaryKey, crudiator.IncludeOnRead),
+		).MustPaginate(crudiator.OFFSET).Build()
+
+		fdb.queueRows([]string{"id"})
+		_, err := editor.Read(crudiator.MapBackedDataForm{}, db, crudiator.NewOffsetPaging(3, 10))
+		require.NoError(t, err)
+		// skips 30 rows and reads 10, rather than skipping 10 and reading 30
+		require.Equal(t, "SELECT `id` FROM `students` LIMIT ?,?", fdb.last().Query)
+		require.Equal(t, []any{30, 10}, fdb.last().Args)
+	}
+}
+
+func TestReadPageWithoutPagination(t *testing.T) {
+	db, _ := newFakeDb(t)
+	editor := newStudentEditor(crudiator.POSTGRESQL, deletedAtFilter, schoolFilter).MustPaginate(crudiator.NONE, "id").Build()
+
+	_, err := editor.ReadPage(crudiator.MapBackedDataForm{}, db, crudiator.NewOffsetPaging(0, 10), false)
+	require.ErrorIs(t, err, crudiator.ErrPaginationNotConfigured)
+}
+
+func TestReadPageWithoutPageable(t *testing.T) {
+	db, fdb := newFakeDb(t)
+	editor := newStudentEditor(crudiator.POSTGRESQL, deletedAtFilter, schoolFilter).MustPaginate(crudiator.OFFSET, "id").Build()
+
+	_, err := editor.ReadPage(crudiator.MapBackedDataForm{}, db, nil, false)
+	require.ErrorIs(t, err, crudiator.ErrNoPageable)
+	require.Empty(t, fdb.all())
+}
+
+func TestReadPageInvalidPaging(t *testing.T) {
+	db, fdb := newFakeDb(t)
+
+	_, err := newStudentEditor(crudiator.POSTGRESQL, deletedAtFilter, schoolFilter).MustPaginate(crudiator.KEYSET, "id").Build().ReadPage(crudiator.MapBackedDataForm{}, db, crudiator.NewKeysetPaging(0, 0), false)
+	require.ErrorIs(t, err, crudiator.ErrInvalidPageSize)
+	_, err = newStudentEditor(crudiator.POSTGRESQL, deletedAtFilter, schoolFilter).MustPaginate(crudiator.OFFSET, "id").Build().ReadPage(crudiator.MapBackedDataForm{}, db, crudiator.NewOffsetPaging(0, -1), false)
+	require.ErrorIs(t, err, crudiator.ErrInvalidPageSize)
+
+	// the next page is taken from the keyset field of the last row
+	editor := crudiator.MustNewEditor(
+		"students",
+		crudiator.POSTGRESQL,
+		crudiator.NewField("id", crudiator.IsPrimaryKey, crudiator.IncludeOnRead),
+		crudiator.NewField("name", crudiator.IncludeAlways),
+		crudiator.NewField("created_at", crudiator.IncludeOnCreate),
+	).MustPaginate(crudiator.KEYSET, "created_at").Build()
+	_, err = editor.ReadPage(crudiator.MapBackedDataForm{}, db, crudiator.NewKeysetPaging(nil, 10), false)
+	require.ErrorIs(t, err, crudiator.ErrKeysetFieldNotRead)
+	require.Empty(t, fdb.all())
+}
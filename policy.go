@@ -0,0 +1,117 @@
+package crudiator
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Returned when a policy denies an operation. Policies may wrap it to give a reason, which
+// can be checked with errors.Is()
+var ErrForbidden = errors.New("forbidden")
+
+// Predicate is an SQL condition restricting the rows accessible to an operation, using '?'
+// placeholders whatever the dialect. The '?' operator of PostgreSQL is written '??'.
+type Predicate struct {
+	SQL  string
+	Args []any
+}
+
+// Creates a predicate
+func Where(sql string, args ...any) Predicate {
+	return Predicate{SQL: sql, Args: args}
+}
+
+// Policy returns the predicates restricting the rows an operation may access, or an error
+// wrapping ErrForbidden to deny it. It is invoked after the pre-action callback.
+type Policy interface {
+	Authorize(ctx context.Context, op Operation, form DataForm) ([]Predicate, error)
+}
+
+// PolicyFunc adapts a function to the Policy interface
+type PolicyFunc func(ctx context.Context, op Operation, form DataForm) ([]Predicate, error)
+
+func (f PolicyFunc) Authorize(ctx context.Context, op Operation, form DataForm) ([]Predicate, error) {
+	return f(ctx, op, form)
+}
+
+// AddPolicy adds a policy to the editor. The predicates of all policies are combined and every
+// policy must allow the operation.
+func (e *Editor) AddPolicy(p Policy) *Editor {
+	e.policies = append(e.policies, p)
+	return e
+}
+
+// Returns the predicates of the editor's policies for the operation
+func (e Editor) authorize(ctx context.Context, op Operation, form DataForm) ([]Predicate, error) {
+	var predicates []Predicate
+	for _, p := range e.policies {
+		pp, err := p.Authorize(ctx, op, form)
+		if err != nil {
+			return nil, err
+		}
+		for _, predicate := range pp {
+			if n := countPlaceholders(e.dialect, predicate.SQL); n != len(predicate.Args) {
+				return nil, errors.Errorf("predicate '%s' expects %d arguments, got %d",
+					predicate.SQL, n, len(predicate.Args))
+			}
+		}
+		predicates = append(predicates, pp...)
+	}
+	return predicates, nil
+}
+
+// A generated statement into which predicates can be inserted
+type statement struct {
+	sql   string
+	at    int  // offset at which predicates are inserted
+	where bool // whether a WHERE clause precedes the offset
+	bound int  // number of parameters bound before the offset
+}
+
+// Returns the statement with the predicates added to its selection and the arguments with those
+// of the predicates inserted accordingly
+func (s statement) with(dialect SQLDialect, args []any, predicates []Predicate) (string, []any) {
+	if len(predicates) == 0 {
+		return s.sql, args
+	}
+
+	var builder strings.Builder
+	var predicateArgs []any
+	count := s.bound
+
+	builder.WriteString(s.sql[:s.at])
+	for i, p := range predicates {
+		if i == 0 && !s.where {
+			builder.WriteString(" WHERE (")
+		} else {
+			builder.WriteString(" AND (")
+		}
+		for j, part := range splitPlaceholders(dialect, p.SQL) {
+			if j > 0 {
+				count++
+				builder.WriteString(placeholder(dialect, count))
+			}
+			builder.WriteString(part)
+		}
+		builder.WriteRune(')')
+		predicateArgs = append(predicateArgs, p.Args...)
+	}
+
+	tail := s.sql[s.at:]
+	if dialect == POSTGRESQL {
+		tail = shiftPlaceholders(tail, len(predicateArgs))
+	}
+	builder.WriteString(tail)
+
+	bound := s.bound
+	if bound > len(args) {
+		bound = len(args)
+	}
+	all := make([]any, 0, len(args)+len(predicateArgs))
+	all = append(all, args[:bound]...)
+	all = append(all, predicateArgs...)
+	all = append(all, args[bound:]...)
+	return builder.String(), all
+}
@@ -0,0 +1,146 @@
+package crudiator_test
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/SharkFourSix/crudiator"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+// teachers only see the students of their school and may not delete them
+var teacherPolicy = crudiator.PolicyFunc(func(ctx context.Context, op crudiator.Operation, form crudiator.DataForm) ([]crudiator.Predicate, error) {
+	if ctx.Value(roleKey{}) != "teacher" {
+		return nil, nil
+	}
+	if op == crudiator.OpDelete {
+		return nil, errors.Wrap(crudiator.ErrForbidden, "teachers may not delete students")
+	}
+	return []crudiator.Predicate{crudiator.Where(`"school_id" = ?`, 3)}, nil
+})
+
+func TestPolicyPredicates(t *testing.T) {
+	db, fdb := newFakeDb(t)
+	editor := newStudentEditor(
+		crudiator.POSTGRESQL,
+		crudiator.NewField("school_id", crudiator.IncludeOnRead),
+		deletedAtFilter,
+		crudiator.NewField("grade", crudiator.IncludeOnRead, crudiator.IsSelectionFilter),
+	).MustPaginate(crudiator.KEYSET, "id").AddPolicy(teacherPolicy).Build()
+	ctx := context.WithValue(context.Background(), roleKey{}, "teacher")
+	form := crudiator.MapBackedDataForm{"id": 1, "name": "Jane", "grade": 5}
+
+	fdb.queueRows([]string{"id"})
+	_, err := editor.ReadContext(ctx, form, db, crudiator.NewKeysetPaging(0, 10))
+	require.NoError(t, err)
+	require.Equal(t, `SELECT "id","name","school_id","deleted_at","grade" FROM "students" WHERE ("deleted_at" IS NULL AND "grade"=$1) AND ("id">$2) AND ("school_id" = $3) ORDER BY "id" ASC LIMIT $4`, fdb.last().Query)
+	require.Equal(t, []any{5, 0, 3, 10}, fdb.last().Args)
+
+	fdb.queueRows([]string{"id"}, []driver.Value{int64(1)})
+	_, err = editor.SingleReadContext(ctx, form, db)
+	require.NoError(t, err)
+	require.Contains(t, fdb.last().Query, `WHERE ("id"=$1) AND ("deleted_at" IS NULL AND "grade"=$2) AND ("school_id" = $3)`)
+	require.Equal(t, []any{1, 5, 3}, fdb.last().Args)
+
+	fdb.queueRows([]string{"id"}, []driver.Value{int64(1)})
+	_, err = editor.UpdateContext(ctx, form, db)
+	require.NoError(t, err)
+	require.Contains(t, fdb.last().Query, `SET "name"=$1 WHERE "id"=$2 AND ("deleted_at" IS NULL AND "grade"=$3) AND ("school_id" = $4) RETURNING "id"`)
+	require.Equal(t, []any{"Jane", 1, 5, 3}, fdb.last().Args)
+
+	fdb.queueRows([]string{"count"}, []driver.Value{int64(2)})
+	_, err = editor.CountContext(ctx, form, db)
+	require.NoError(t, err)
+	require.Equal(t, `SELECT COUNT(*) FROM "students" WHERE ("deleted_at" IS NULL AND "grade"=$1) AND ("school_id" = $2)`, fdb.last().Query)
+
+	fdb.queueRows([]string{"exists"}, []driver.Value{true})
+	_, err = editor.ExistsContext(ctx, form, db)
+	require.NoError(t, err)
+	require.Equal(t, `SELECT EXISTS(SELECT 1 FROM "students" WHERE ("deleted_at" IS NULL AND "grade"=$1) AND ("school_id" = $2))`, fdb.last().Query)
+
+	fdb.queueRows([]string{"count"})
+	_, err = editor.AggregateContext(ctx, form, db, []string{"grade"}, crudiator.CountAll())
+	require.NoError(t, err)
+	require.Contains(t, fdb.last().Query, `WHERE ("deleted_at" IS NULL AND "grade"=$1) AND ("school_id" = $2) GROUP BY`)
+
+	executed := len(fdb.all())
+	_, err = editor.DeleteContext(ctx, form, db)
+	require.ErrorIs(t, err, crudiator.ErrForbidden)
+	require.Len(t, fdb.all(), executed, "no statement must be executed")
+
+	// other roles are not restricted
+	fdb.queueRows([]string{"id"})
+	_, err = editor.Read(form, db, crudiator.NewKeysetPaging(0, 10))
+	require.NoError(t, err)
+	require.Equal(t, []any{5, 0, 10}, fdb.last().Args)
+}
+
+func TestPolicyWithoutFilters(t *testing.T) {
+	db, fdb := newFakeDb(t)
+	editor := crudiator.MustNewEditor(
+		"students",
+		crudiator.MYSQL,
+		crudiator.NewField("id", crudiator.IsPrimaryKey, crudiator.IncludeOnRead),
+		crudiator.NewField("school_id", crudiator.IncludeOnRead),
+	).MustPaginate(crudiator.OFFSET).AddPolicy(teacherPolicy).Build()
+	ctx := context.WithValue(context.Background(), roleKey{}, "teacher")
+
+	fdb.queueRows([]string{"id"})
+	_, err := editor.ReadContext(ctx, crudiator.MapBackedDataForm{}, db, crudiator.NewOffsetPaging(2, 10))
+	require.NoError(t, err)
+	require.Equal(t, "SELECT `id`,`school_id` FROM `students` WHERE (\"school_id\" = ?) LIMIT ?,?", fdb.last().Query)
+	require.Equal(t, []any{3, 20, 10}, fdb.last().Args)
+
+	bad := crudiator.PolicyFunc(func(ctx context.Context, op crudiator.Operation, form crudiator.DataForm) ([]crudiator.Predicate, error) {
+		return []crudiator.Predicate{crudiator.Where(`"school_id" = ?`)}, nil
+	})
+	editor = crudiator.MustNewEditor(
+		"students",
+		crudiator.MYSQL,
+		crudiator.NewField("id", crudiator.IsPrimaryKey, crudiator.IncludeOnRead),
+	).AddPolicy(bad).Build()
+	_, err = editor.Read(crudiator.MapBackedDataForm{}, db)
+	require.ErrorContains(t, err, "expects 1 arguments, got 0")
+}
+
+func TestPolicyQuestionMarks(t *testing.T) {
+	db, fdb := newFakeDb(t)
+	predicates := []crudiator.Predicate{
+		crudiator.Where(`"name" <> 'who?' AND "tags" ?? ?`, "featured"),
+		crudiator.Where(`"tags" ?| ARRAY['a','b'] AND "tags" ?& ARRAY['c']`),
+		crudiator.Where(`"grade" >= ? /* at least? */`, 2),
+	}
+	editor := crudiator.MustNewEditor(
+		"students",
+		crudiator.POSTGRESQL,
+		crudiator.NewField("id", crudiator.IsPrimaryKey, crudiator.IncludeOnRead),
+		crudiator.NewField("tags", crudiator.IncludeOnRead),
+	).MustPaginate(crudiator.KEYSET, "id").AddPolicy(crudiator.PolicyFunc(func(ctx context.Context, op crudiator.Operation, form crudiator.DataForm) ([]crudiator.Predicate, error) {
+		return predicates, nil
+	})).Build()
+
+	fdb.queueRows([]string{"id"})
+	_, err := editor.Read(crudiator.MapBackedDataForm{}, db, crudiator.NewKeysetPaging(0, 10))
+	require.NoError(t, err)
+	require.Equal(t, `SELECT "id","tags" FROM "students" WHERE ("id">$1) AND ("name" <> 'who?' AND "tags" ? $2) AND ("tags" ?| ARRAY['a','b'] AND "tags" ?& ARRAY['c']) AND ("grade" >= $3 /* at least? */) ORDER BY "id" ASC LIMIT $4`, fdb.last().Query)
+	require.Equal(t, []any{0, "featured", 2, 10}, fdb.last().Args)
+
+	predicates = []crudiator.Predicate{crudiator.Where(`"name" = '?'`, "Jane")}
+	_, err = editor.Read(crudiator.MapBackedDataForm{}, db, crudiator.NewKeysetPaging(0, 10))
+	require.ErrorContains(t, err, "expects 0 arguments, got 1")
+
+	// MySQL escapes quotes of string literals with backslashes
+	editor = crudiator.MustNewEditor(
+		"students",
+		crudiator.MYSQL,
+		crudiator.NewField("id", crudiator.IsPrimaryKey, crudiator.IncludeOnRead),
+	).AddPolicy(crudiator.PolicyFunc(func(ctx context.Context, op crudiator.Operation, form crudiator.DataForm) ([]crudiator.Predicate, error) {
+		return []crudiator.Predicate{crudiator.Where(`name <> 'it\'s ?' AND grade = ?`, 2)}, nil
+	})).Build()
+	fdb.queueRows([]string{"id"})
+	_, err = editor.Read(crudiator.MapBackedDataForm{}, db)
+	require.NoError(t, err)
+	require.Equal(t, []any{2}, fdb.last().Args)
+}
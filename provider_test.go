@@ -0,0 +1,94 @@
+package crudiator_test
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/SharkFourSix/crudiator"
+	"github.com/stretchr/testify/require"
+)
+
+type userKey struct{}
+
+func TestValueProviders(t *testing.T) {
+	db, fdb := newFakeDb(t)
+	currentUser := func(ctx context.Context, form crudiator.DataForm) (any, error) {
+		user, ok := ctx.Value(userKey{}).(int)
+		if !ok {
+			return nil, errors.New("no user")
+		}
+		return user, nil
+	}
+	slug := func(ctx context.Context, form crudiator.DataForm) (any, error) {
+		return strings.ToLower(strings.ReplaceAll(form.Get("title").(string), " ", "-")), nil
+	}
+
+	editor := crudiator.MustNewEditor(
+		"posts",
+		crudiator.POSTGRESQL,
+		crudiator.NewField("id", crudiator.IsPrimaryKey, crudiator.IncludeOnRead),
+		crudiator.NewField("title", crudiator.IncludeAlways),
+		crudiator.NewField("slug", crudiator.IncludeOnRead, crudiator.ProvideAlways(slug)),
+		crudiator.NewField("created_by", crudiator.IncludeOnRead, crudiator.ProvideOnCreate(currentUser)),
+		crudiator.NewField("updated_by", crudiator.IncludeOnRead, crudiator.ProvideOnUpdate(currentUser)),
+	).Build()
+
+	ctx := context.WithValue(context.Background(), userKey{}, 42)
+
+	fdb.queueRows([]string{"id"}, []driver.Value{int64(1)})
+	form := crudiator.MapBackedDataForm{"title": "Hello World", "created_by": 1}
+	_, err := editor.CreateContext(ctx, form, db)
+	require.NoError(t, err)
+	require.Contains(t, fdb.last().Query, `("title","slug","created_by") VALUES ($1,$2,$3)`)
+	require.Equal(t, []any{"Hello World", "hello-world", 42}, fdb.last().Args)
+
+	fdb.queueRows([]string{"id"}, []driver.Value{int64(1)})
+	form = crudiator.MapBackedDataForm{"id": 1, "title": "Bye World", "updated_by": 1}
+	_, err = editor.UpdateContext(ctx, form, db)
+	require.NoError(t, err)
+	require.Contains(t, fdb.last().Query, `SET "title"=$1,"slug"=$2,"updated_by"=$3 WHERE "id"=$4`)
+	require.Equal(t, []any{"Bye World", "bye-world", 42, 1}, fdb.last().Args)
+
+	_, err = editor.Create(crudiator.MapBackedDataForm{"title": "Hello"}, db)
+	require.ErrorContains(t, err, "created_by")
+	require.Len(t, fdb.all(), 2, "no statement must be executed")
+}
+
+func TestCreateProviderOnUpdateWritableField(t *testing.T) {
+	db, fdb := newFakeDb(t)
+	generated := func(ctx context.Context, form crudiator.DataForm) (any, error) {
+		return "GEN-1", nil
+	}
+	editor := crudiator.MustNewEditor(
+		"orders",
+		crudiator.POSTGRESQL,
+		crudiator.NewField("id", crudiator.IsPrimaryKey, crudiator.IncludeOnRead),
+		crudiator.NewField("code", crudiator.IncludeOnRead, crudiator.IncludeOnUpdate, crudiator.ProvideOnCreate(generated), crudiator.Required),
+		crudiator.NewField("created_at", crudiator.IncludeOnRead, crudiator.IncludeOnUpdate, crudiator.AutoCreateTimestamp),
+	).Build()
+
+	// the values written on create are managed, but those written on update come from the form
+	create := editor.SanitizeForm(crudiator.OpCreate, crudiator.MapBackedDataForm{"code": "X", "created_at": "2024-01-01"})
+	require.Empty(t, create)
+	update := editor.SanitizeForm(crudiator.OpUpdate, crudiator.MapBackedDataForm{"id": 1, "code": "X", "created_at": "2024-01-01"})
+	require.Equal(t, crudiator.MapBackedDataForm{"id": 1, "code": "X", "created_at": "2024-01-01"}, update)
+
+	fdb.queueRows([]string{"id"}, []driver.Value{int64(1)})
+	_, err := editor.Create(crudiator.MapBackedDataForm{}, db)
+	require.NoError(t, err)
+	require.Equal(t, "GEN-1", fdb.last().Args[0])
+
+	fdb.queueRows([]string{"id"}, []driver.Value{int64(1)})
+	_, err = editor.Update(crudiator.MapBackedDataForm{"id": 1, "code": "X-2", "created_at": "2024-01-01"}, db)
+	require.NoError(t, err)
+	require.Contains(t, fdb.last().Query, `SET "code"=$1,"created_at"=$2 WHERE "id"=$3`)
+	require.Equal(t, []any{"X-2", "2024-01-01", 1}, fdb.last().Args)
+
+	_, err = editor.Update(crudiator.MapBackedDataForm{"id": 1, "created_at": "2024-01-01"}, db)
+	var failures crudiator.ValidationErrors
+	require.ErrorAs(t, err, &failures)
+	require.Contains(t, failures, "code")
+}
@@ -0,0 +1,308 @@
+package crudiator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// RelationKind identifies how the rows of two editors are related
+type RelationKind int
+
+const (
+	// The related rows reference the row through a foreign key
+	HasManyRelation RelationKind = iota + 1
+	// The row references the related row through a foreign key
+	BelongsToRelation
+	// The rows are related through the rows of a link table
+	ManyToManyRelation
+)
+
+// Column of the key used to attach related rows to their rows, which is removed once attached
+const relationKeyColumn = "__crudiator_key"
+
+// Maximum number of keys matched by a single query reading related rows, which keeps the number
+// of parameters below the limits of the databases
+const relationBatchSize = 500
+
+type relation struct {
+	name       string
+	kind       RelationKind
+	editor     *Editor
+	foreignKey string
+	linkTable  string
+	linkLocal  string // link table column referencing the rows
+	linkRemote string // link table column referencing the related rows
+	cascade    CascadeAction
+}
+
+type includeKey struct{}
+
+// Include returns a context which makes the reads nest the rows of the given relations in each
+// row. Relations of related rows are named with a dotted path, i.e. "students.guardian"
+func Include(ctx context.Context, relations ...string) context.Context {
+	included, _ := ctx.Value(includeKey{}).([]string)
+	return context.WithValue(ctx, includeKey{}, append(included[:len(included):len(included)], relations...))
+}
+
+// HasMany declares that the rows of related reference the rows of the editor through their
+// foreignKey column. Included related rows are nested as a []DbRow.
+func (e *Editor) HasMany(name string, related *Editor, foreignKey string) *Editor {
+	return e.addRelation(relation{name: name, kind: HasManyRelation, editor: related, foreignKey: foreignKey})
+}
+
+// BelongsTo declares that the rows of the editor reference the rows of related through their
+// foreignKey column. An included related row is nested as a DbRow, or nil if there is none.
+func (e *Editor) BelongsTo(name string, related *Editor, foreignKey string) *Editor {
+	return e.addRelation(relation{name: name, kind: BelongsToRelation, editor: related, foreignKey: foreignKey})
+}
+
+// ManyToMany declares that the rows of the editor and of related are related through the rows
+// of linkTable, whose localKey column references the rows of the editor and relatedKey column
+// the rows of related. Included related rows are nested as a []DbRow.
+func (e *Editor) ManyToMany(name string, related *Editor, linkTable string, localKey string, relatedKey string) *Editor {
+	return e.addRelation(relation{
+		name:       name,
+		kind:       ManyToManyRelation,
+		editor:     related,
+		linkTable:  linkTable,
+		linkLocal:  localKey,
+		linkRemote: relatedKey,
+	})
+}
+
+func (e *Editor) addRelation(r relation) *Editor {
+	if r.editor == nil {
+		panic(errors.Errorf("relation '%s' has no editor", r.name))
+	}
+	for _, x := range e.relations {
+		if x.name == r.name {
+			panic(errors.Errorf("duplicate relation '%s'", r.name))
+		}
+	}
+	e.relations = append(e.relations, r)
+	return e
+}
+
+func (e Editor) relation(name string) (relation, bool) {
+	for _, r := range e.relations {
+		if r.name == name {
+			return r, true
+		}
+	}
+	return relation{}, false
+}
+
+// Loads the relations included through the context of the operation into the rows
+func (e Editor) loadIncluded(ctx context.Context, q queryer, rows []DbRow) error {
+	included, _ := ctx.Value(includeKey{}).([]string)
+	if len(included) == 0 {
+		return nil
+	}
+	return e.loadRelations(ctx, q, rows, included)
+}
+
+// Loads the relations named by the paths into the rows, one query per relation
+func (e Editor) loadRelations(ctx context.Context, q queryer, rows []DbRow, paths []string) error {
+	var names []string
+	nested := make(map[string][]string)
+	for _, path := range paths {
+		name, rest, _ := strings.Cut(path, ".")
+		if _, ok := nested[name]; !ok {
+			names = append(names, name)
+			nested[name] = nil
+		}
+		if rest != "" {
+			nested[name] = append(nested[name], rest)
+		}
+	}
+
+	for _, name := range names {
+		r, ok := e.relation(name)
+		if !ok {
+			return errors.Errorf("unknown relation '%s'", name)
+		}
+		related := r.editor.forRole(ctx)
+		if err := related.checkOperation(OpRead); err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			continue
+		}
+		children, err := e.loadRelation(ctx, q, r, related, rows)
+		if err != nil {
+			return errors.Wrapf(err, "relation '%s'", name)
+		}
+		if len(nested[name]) > 0 {
+			if err := related.loadRelations(ctx, q, children, nested[name]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Reads the rows related to rows, attaches them and returns them
+func (e Editor) loadRelation(ctx context.Context, q queryer, r relation, related Editor, rows []DbRow) ([]DbRow, error) {
+	// the column of the rows matched against the key of the related rows
+	local := e.primaryKeyField
+	if r.kind == BelongsToRelation {
+		local = r.foreignKey
+	}
+	if !rows[0].Has(local) {
+		return nil, errors.Errorf("field '%s' is not read", local)
+	}
+
+	var keys []any
+	seen := make(map[string]bool)
+	for _, row := range rows {
+		if v := row.Get(local); v != nil && !seen[relationKey(v)] {
+			seen[relationKey(v)] = true
+			keys = append(keys, v)
+		}
+	}
+
+	var children []DbRow
+	if len(keys) > 0 {
+		var err error
+		children, err = related.readRelated(ctx, q, r, keys)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	grouped := make(map[string][]DbRow)
+	for _, child := range children {
+		k := relationKey(child.Get(relationKeyColumn))
+		child.Remove(relationKeyColumn)
+		grouped[k] = append(grouped[k], child)
+	}
+	for _, row := range rows {
+		var matches []DbRow
+		if v := row.Get(local); v != nil {
+			matches = grouped[relationKey(v)]
+		}
+		if r.kind == BelongsToRelation {
+			var parent DbRow
+			if len(matches) > 0 {
+				parent = matches[0]
+			}
+			row[r.name] = parent
+		} else {
+			if matches == nil {
+				matches = []DbRow{}
+			}
+			row[r.name] = matches
+		}
+	}
+	return children, nil
+}
+
+// Reads the rows of the relation whose key is one of keys, by batches of relationBatchSize keys
+func (e Editor) readRelated(ctx context.Context, q queryer, r relation, keys []any) ([]DbRow, error) {
+	var key, join string
+	switch r.kind {
+	case HasManyRelation:
+		key = e.qualify(r.foreignKey)
+	case BelongsToRelation:
+		key = e.qualify(e.primaryKeyField)
+	case ManyToManyRelation:
+		link := fmt.Sprintf("%c%s%c", e.quoteRune, r.linkTable, e.quoteRune)
+		key = fmt.Sprintf("%s.%c%s%c", link, e.quoteRune, r.linkLocal, e.quoteRune)
+		join = fmt.Sprintf(" JOIN %s ON %s.%c%s%c=%s", link, link, e.quoteRune, r.linkRemote, e.quoteRune, e.qualify(e.primaryKeyField))
+	}
+
+	predicates, err := e.authorize(ctx, OpRead, MapBackedDataForm{})
+	if err != nil {
+		return nil, err
+	}
+	var tenant []any
+	if e.tenantField != "" {
+		value, err := e.tenantValue(ctx)
+		if err != nil {
+			return nil, err
+		}
+		tenant = append(tenant, value)
+	}
+
+	s := e.relationStatement(key, join)
+	var related []DbRow
+	for len(keys) > 0 {
+		batch := keys
+		if len(batch) > relationBatchSize {
+			batch = batch[:relationBatchSize]
+		}
+		keys = keys[len(batch):]
+
+		in := strings.TrimSuffix(strings.Repeat("?,", len(batch)), ",")
+		query, args := s.with(e.dialect, tenant, append(predicates[:len(predicates):len(predicates)], Where(key+" IN ("+in+")", batch...)))
+		e.logger.Debug("relation '%s' statement => %s", r.name, query)
+
+		rows, err := q.QueryContext(ctx, query, args...)
+		if err != nil {
+			return nil, err
+		}
+		scanned, err := e.scanRows(rows)
+		rows.Close()
+		if err != nil {
+			return nil, err
+		}
+		related = append(related, scanned...)
+	}
+	return related, nil
+}
+
+// Returns the statement selecting the rows of the editor along with the key column
+func (e Editor) relationStatement(key string, join string) statement {
+	var builder strings.Builder
+	var filters []string
+	var bound int
+
+	builder.WriteString("SELECT ")
+	for _, f := range e.readFields {
+		builder.WriteString(e.qualify(e.unquote(f)))
+		builder.WriteRune(',')
+	}
+	builder.WriteString(key)
+	builder.WriteString(" AS ")
+	builder.WriteString(fmt.Sprintf("%c%s%c", e.quoteRune, relationKeyColumn, e.quoteRune))
+	builder.WriteString(" FROM ")
+	builder.WriteString(e.tableNameQuoted)
+	builder.WriteString(join)
+
+	for _, f := range e.fields {
+		if !f.SelectionFilter {
+			continue
+		}
+		switch {
+		case f.NullCheck == FieldMustBeNull:
+			filters = append(filters, e.fieldExpression(f, true)+" IS NULL")
+		case f.NullCheck == FieldMustNotBeNull:
+			filters = append(filters, e.fieldExpression(f, true)+" IS NOT NULL")
+		case f.Name == e.tenantField:
+			bound++
+			filters = append(filters, e.qualify(f.Name)+"="+placeholder(e.dialect, bound))
+		}
+	}
+	if len(filters) > 0 {
+		builder.WriteString(" WHERE (")
+		builder.WriteString(strings.Join(filters, " AND "))
+		builder.WriteRune(')')
+	}
+	return statement{sql: builder.String(), at: builder.Len(), where: len(filters) > 0, bound: bound}
+}
+
+// Returns the column qualified with the name of the table
+func (e Editor) qualify(column string) string {
+	return fmt.Sprintf("%c%s%c.%c%s%c", e.quoteRune, e.tableName, e.quoteRune, e.quoteRune, column, e.quoteRune)
+}
+
+// Returns the key matching the values of related columns, whatever their Go type
+func relationKey(v any) string {
+	if b, ok := v.([]byte); ok {
+		return string(b)
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,88 @@
+package crudiator_test
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/SharkFourSix/crudiator"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHasManyRelation(t *testing.T) {
+	db, fdb := newFakeDb(t)
+	schools, _, _ := newSchoolEditors()
+	ctx := crudiator.Include(context.Background(), "students.courses")
+
+	fdb.queueRows([]string{"id", "name"},
+		[]driver.Value{int64(1), "Chichiri"},
+		[]driver.Value{int64(2), "Bwaila"},
+	)
+	fdb.queueRows([]string{"id", "name", "school_id", "__crudiator_key"},
+		[]driver.Value{int64(10), "Jane", int64(1), int64(1)},
+		[]driver.Value{int64(11), "John", int64(1), int64(1)},
+	)
+	fdb.queueRows([]string{"id", "title", "__crudiator_key"},
+		[]driver.Value{int64(100), "Biology", int64(10)},
+		[]driver.Value{int64(100), "Biology", int64(11)},
+		[]driver.Value{int64(101), "History", int64(11)},
+	)
+	rows, err := schools.ReadContext(ctx, crudiator.MapBackedDataForm{}, db)
+	require.NoError(t, err)
+
+	queries := fdb.all()
+	require.Len(t, queries, 3)
+	require.Equal(t, `SELECT "students"."id","students"."name","students"."school_id","students"."school_id" AS "__crudiator_key" FROM "students" WHERE ("students"."deleted_at" IS NULL) AND ("students"."school_id" IN ($1,$2))`, queries[1].Query)
+	require.Equal(t, []any{int64(1), int64(2)}, queries[1].Args)
+	require.Equal(t, `SELECT "courses"."id","courses"."title","enrolments"."student_id" AS "__crudiator_key" FROM "courses" JOIN "enrolments" ON "enrolments"."course_id"="courses"."id" WHERE ("enrolments"."student_id" IN ($1,$2))`, queries[2].Query)
+
+	students := rows[0].Get("students").([]crudiator.DbRow)
+	require.Len(t, students, 2)
+	require.False(t, students[0].Has("__crudiator_key"))
+	require.Len(t, students[0].Get("courses"), 1)
+	require.Len(t, students[1].Get("courses"), 2)
+	require.Equal(t, []crudiator.DbRow{}, rows[1].Get("students"))
+}
+
+func TestRelationKeyBatches(t *testing.T) {
+	db, fdb := newFakeDb(t)
+	schools, _, _ := newSchoolEditors()
+	ctx := crudiator.Include(context.Background(), "students")
+
+	var rows [][]driver.Value
+	for id := 1; id <= 501; id++ {
+		rows = append(rows, []driver.Value{int64(id), "school"})
+	}
+	fdb.queueRows([]string{"id", "name"}, rows...)
+	cols := []string{"id", "name", "school_id", "__crudiator_key"}
+	fdb.queueRows(cols, []driver.Value{int64(10), "Jane", int64(1), int64(1)})
+	fdb.queueRows(cols, []driver.Value{int64(11), "John", int64(501), int64(501)})
+	read, err := schools.ReadContext(ctx, crudiator.MapBackedDataForm{}, db)
+	require.NoError(t, err)
+
+	queries := fdb.all()
+	require.Len(t, queries, 3)
+	require.Len(t, queries[1].Args, 500)
+	require.Equal(t, []any{int64(501)}, queries[2].Args)
+	require.Contains(t, queries[2].Query, `("students"."school_id" IN ($1))`)
+
+	require.Equal(t, "Jane", read[0].Get("students").([]crudiator.DbRow)[0].Get("name"))
+	require.Equal(t, "John", read[500].Get("students").([]crudiator.DbRow)[0].Get("name"))
+	require.Equal(t, []crudiator.DbRow{}, read[1].Get("students"))
+}
+
+func TestBelongsToRelation(t *testing.T) {
+	db, fdb := newFakeDb(t)
+	_, students, _ := newSchoolEditors()
+	ctx := crudiator.Include(context.Background(), "school")
+
+	fdb.queueRows([]string{"id", "name", "school_id"}, []driver.Value{int64(10), "Jane", int64(1)})
+	fdb.queueRows([]string{"id", "name", "__crudiator_key"}, []driver.Value{int64(1), "Chichiri", int64(1)})
+	row, err := students.SingleReadContext(ctx, crudiator.MapBackedDataForm{"id": 10}, db)
+	require.NoError(t, err)
+	require.Equal(t, `SELECT "schools"."id","schools"."name","schools"."id" AS "__crudiator_key" FROM "schools" WHERE ("schools"."id" IN ($1))`, fdb.last().Query)
+	require.Equal(t, crudiator.DbRow{"id": int64(1), "name": "Chichiri"}, row.Get("school"))
+
+	_, err = students.ReadContext(crudiator.Include(context.Background(), "guardian"), crudiator.MapBackedDataForm{}, db)
+	require.ErrorContains(t, err, "unknown relation 'guardian'")
+}
@@ -0,0 +1,123 @@
+package crudiator
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// Restricts reading the field to the given roles. Rows read by other roles do not carry the
+	// field, and cannot aggregate it. See 'Editor.RoleFrom()'
+	ReadableBy = func(roles ...string) FieldOption {
+		return func(f *Field) { f.ReadRoles = append(f.ReadRoles, roles...) }
+	}
+
+	// Restricts writing the field on create to the given roles. See 'Editor.RoleFrom()'
+	CreatableBy = func(roles ...string) FieldOption {
+		return func(f *Field) { f.CreateRoles = append(f.CreateRoles, roles...) }
+	}
+
+	// Restricts writing the field on update to the given roles. See 'Editor.RoleFrom()'
+	UpdatableBy = func(roles ...string) FieldOption {
+		return func(f *Field) { f.UpdateRoles = append(f.UpdateRoles, roles...) }
+	}
+
+	// Restricts writing the field on create and update to the given roles
+	WritableBy = func(roles ...string) FieldOption {
+		return func(f *Field) {
+			f.CreateRoles = append(f.CreateRoles, roles...)
+			f.UpdateRoles = append(f.UpdateRoles, roles...)
+		}
+	}
+)
+
+// RoleFrom sets the function returning the role of the user performing an operation. Fields
+// restricted to other roles are left out of the statements of the operation.
+func (e *Editor) RoleFrom(role func(ctx context.Context) string) *Editor {
+	e.role = role
+	return e
+}
+
+func allows(roles []string, role string) bool {
+	if len(roles) == 0 {
+		return true
+	}
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the roles named by the role options of the fields
+func (e *Editor) rolesOfFields() map[string]bool {
+	roles := make(map[string]bool)
+	for _, f := range e.fields {
+		for _, list := range [][]string{f.ReadRoles, f.CreateRoles, f.UpdateRoles} {
+			for _, role := range list {
+				if role == "" {
+					panic(errors.Errorf("field '%s' is restricted to the empty role", f.Name))
+				}
+				roles[role] = true
+			}
+		}
+	}
+	return roles
+}
+
+// Returns the editor of the role found in the context, which is built on first use
+func (e Editor) forRole(ctx context.Context) Editor {
+	if e.roleEditors == nil {
+		return e
+	}
+	var role string
+	if e.role != nil {
+		role = e.role(ctx)
+	}
+	if !e.declaredRoles[role] {
+		// restricted fields are hidden from every other role alike
+		role = ""
+	}
+	if re, ok := e.roleEditors.Load(role); ok {
+		return *re.(*Editor)
+	}
+
+	re := e
+	re.roleEditors = nil
+	re.declaredRoles = nil
+	re.roleBound = true
+	re.fields = make([]Field, len(e.fields))
+	for i, f := range e.fields {
+		if !allows(f.ReadRoles, role) {
+			f.Read = false
+		}
+		if !allows(f.CreateRoles, role) {
+			f.Create = false
+		}
+		if !allows(f.UpdateRoles, role) {
+			f.Update = false
+		}
+		re.fields[i] = f
+	}
+	re.Build()
+
+	actual, _ := e.roleEditors.LoadOrStore(role, &re)
+	return *actual.(*Editor)
+}
+
+// Prepares the cache of role editors if any field is restricted to some roles
+func (e *Editor) buildRoleEditors() *Editor {
+	e.roleEditors = nil
+	e.declaredRoles = nil
+	if e.roleBound {
+		return e
+	}
+	if roles := e.rolesOfFields(); len(roles) > 0 {
+		e.roleEditors = &sync.Map{}
+		e.declaredRoles = roles
+	}
+	return e
+}
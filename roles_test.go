@@ -0,0 +1,105 @@
+package crudiator_test
+
+import (
+	"context"
+	"database/sql/driver"
+	"strings"
+	"testing"
+
+	"github.com/SharkFourSix/crudiator"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRolePermissions(t *testing.T) {
+	db, fdb := newFakeDb(t)
+	editor := newEmployeeEditor()
+	hr := context.WithValue(context.Background(), roleKey{}, "hr")
+	staff := context.WithValue(context.Background(), roleKey{}, "staff")
+
+	fdb.queueRows([]string{"id"})
+	_, err := editor.ReadContext(hr, crudiator.MapBackedDataForm{}, db)
+	require.NoError(t, err)
+	require.Equal(t, `SELECT "id","name","salary","grade" FROM "employees"`, fdb.last().Query)
+
+	fdb.queueRows([]string{"id"})
+	_, err = editor.ReadContext(staff, crudiator.MapBackedDataForm{}, db)
+	require.NoError(t, err)
+	require.Equal(t, `SELECT "id","name","grade" FROM "employees"`, fdb.last().Query)
+
+	fdb.queueRows([]string{"id"}, []driver.Value{int64(1)})
+	_, err = editor.UpdateContext(hr, crudiator.MapBackedDataForm{"id": 1, "name": "Jane", "salary": 10, "grade": "B"}, db)
+	require.NoError(t, err)
+	require.Equal(t, `UPDATE "employees" SET "name"=$1,"salary"=$2,"grade"=$3 WHERE "id"=$4 RETURNING "id","name","salary","grade"`, fdb.last().Query)
+
+	fdb.queueRows([]string{"id"}, []driver.Value{int64(1)})
+	_, err = editor.CreateContext(staff, crudiator.MapBackedDataForm{"name": "Jane", "grade": "A"}, db)
+	require.NoError(t, err)
+	require.Equal(t, `INSERT INTO "employees"("name","grade") VALUES ($1,$2) RETURNING "id","name","grade"`, fdb.last().Query)
+
+	_, err = editor.UpdateContext(staff, crudiator.MapBackedDataForm{"id": 1, "grade": "A"}, db)
+	var unexpected *crudiator.UnexpectedFieldsError
+	require.ErrorAs(t, err, &unexpected)
+	require.Equal(t, []string{"grade"}, unexpected.Fields)
+
+	_, err = editor.AggregateContext(staff, crudiator.MapBackedDataForm{}, db, nil, crudiator.Avg("salary"))
+	require.ErrorContains(t, err, "unknown aggregate field 'salary'")
+
+	// without a context, the empty role applies
+	fdb.queueRows([]string{"id"})
+	_, err = editor.Read(crudiator.MapBackedDataForm{}, db)
+	require.NoError(t, err)
+	require.Equal(t, `SELECT "id","name","grade" FROM "employees"`, fdb.last().Query)
+}
+
+func BenchmarkRoleRead(b *testing.B) {
+	db, fdb := newFakeDb(b)
+	fdb.repeat = &fakeResult{Columns: []string{"id"}}
+	editor := newEmployeeEditor()
+	hr := context.WithValue(context.Background(), roleKey{}, "hr")
+	form := crudiator.MapBackedDataForm{}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := editor.ReadContext(hr, form, db); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// counts the editors built through the create statements they log
+type buildCounter struct {
+	crudiator.Logger
+	builds int
+}
+
+func (c *buildCounter) Debug(m string, args ...any) {
+	if strings.HasPrefix(m, "create statement") {
+		c.builds++
+	}
+}
+
+func TestUndeclaredRolesShareStatements(t *testing.T) {
+	db, fdb := newFakeDb(t)
+	counter := &buildCounter{Logger: crudiator.NewNopLogger()}
+	editor := crudiator.MustNewEditor(
+		"employees",
+		crudiator.POSTGRESQL,
+		crudiator.NewField("id", crudiator.IsPrimaryKey, crudiator.IncludeOnRead),
+		crudiator.NewField("salary", crudiator.IncludeOnRead, crudiator.ReadableBy("hr")),
+	).SetLogger(counter).RoleFrom(roleOf).Build()
+	counter.builds = 0
+
+	for _, role := range []string{"staff", "guest", "intern", "", "staff"} {
+		fdb.queueRows([]string{"id"})
+		_, err := editor.ReadContext(context.WithValue(context.Background(), roleKey{}, role), crudiator.MapBackedDataForm{}, db)
+		require.NoError(t, err)
+		require.Equal(t, `SELECT "id" FROM "employees"`, fdb.last().Query)
+	}
+	require.Equal(t, 1, counter.builds)
+
+	fdb.queueRows([]string{"id"})
+	_, err := editor.ReadContext(context.WithValue(context.Background(), roleKey{}, "hr"), crudiator.MapBackedDataForm{}, db)
+	require.NoError(t, err)
+	require.Equal(t, `SELECT "id","salary" FROM "employees"`, fdb.last().Query)
+	require.Equal(t, 2, counter.builds)
+}
@@ -0,0 +1,128 @@
+package crudiator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Operation identifies one of the CRUD operations performed by an editor
+type Operation int
+
+const (
+	OpCreate Operation = iota + 1
+	OpRead
+	OpUpdate
+	OpDelete
+)
+
+func (o Operation) String() string {
+	switch o {
+	case OpCreate:
+		return "create"
+	case OpRead:
+		return "read"
+	case OpUpdate:
+		return "update"
+	case OpDelete:
+		return "delete"
+	}
+	return "unknown"
+}
+
+// UnexpectedFieldsError is returned by Create and Update in strict mode when the form carries
+// keys that the client is not allowed to send for that operation.
+type UnexpectedFieldsError struct {
+	Operation Operation
+	Fields    []string // sorted
+}
+
+func (e *UnexpectedFieldsError) Error() string {
+	return fmt.Sprintf("unexpected fields for %s: %s", e.Operation, strings.Join(e.Fields, ", "))
+}
+
+// Strict toggles strict mode, where Create and Update reject forms carrying keys not accepted by
+// 'SanitizeForm()' with an *UnexpectedFieldsError.
+func (e *Editor) Strict(b bool) *Editor {
+	e.strict = b
+	return e
+}
+
+// Computes the form keys accepted for each operation
+func (e *Editor) buildAcceptedFormKeys() *Editor {
+	e.acceptedFormKeys = make(map[Operation]map[string]bool)
+	selection := make(map[string]bool)
+	if e.primaryKeyField != "" {
+		selection[e.primaryKeyField] = true
+	}
+	for _, f := range e.fields {
+		if f.SelectionFilter && f.NullCheck == NoFieldNullCheck && f.Name != e.tenantField {
+			selection[f.Name] = true
+		}
+	}
+
+	create := make(map[string]bool)
+	update := make(map[string]bool)
+	for k := range selection {
+		update[k] = true
+	}
+	for _, f := range e.fields {
+		// managed fields are set by the editor
+		if f.Create && !f.managedOn(OpCreate) {
+			create[f.Name] = true
+		}
+		if f.Update && !f.managedOn(OpUpdate) {
+			update[f.Name] = true
+		}
+	}
+
+	deletion := make(map[string]bool)
+	for k := range selection {
+		deletion[k] = true
+	}
+	for _, f := range e.fields {
+		if f.Version {
+			// carries the version read by the client
+			update[f.Name] = true
+			deletion[f.Name] = true
+		}
+	}
+
+	e.acceptedFormKeys[OpCreate] = create
+	e.acceptedFormKeys[OpRead] = selection
+	e.acceptedFormKeys[OpUpdate] = update
+	e.acceptedFormKeys[OpDelete] = deletion
+	return e
+}
+
+// Returns the sorted form keys not accepted for the operation
+func (e Editor) unexpectedFormKeys(op Operation, form DataForm) []string {
+	var unexpected []string
+	accepted := e.acceptedFormKeys[op]
+	form.Iterate(func(key string, value any) {
+		if !accepted[key] {
+			unexpected = append(unexpected, key)
+		}
+	})
+	sort.Strings(unexpected)
+	return unexpected
+}
+
+func (e Editor) checkFormKeys(op Operation, form DataForm) error {
+	if !e.strict {
+		return nil
+	}
+	if unexpected := e.unexpectedFormKeys(op, form); len(unexpected) > 0 {
+		return &UnexpectedFieldsError{Operation: op, Fields: unexpected}
+	}
+	return nil
+}
+
+// SanitizeForm removes the keys of the form which are not accepted for the operation and
+// returns the same form.
+func (e Editor) SanitizeForm(op Operation, form DataForm) DataForm {
+	for _, key := range e.unexpectedFormKeys(op, form) {
+		form.Remove(key)
+	}
+	return form
+}
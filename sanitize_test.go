@@ -0,0 +1,56 @@
+package crudiator_test
+
+import (
+	"database/sql/driver"
+	"testing"
+
+	"github.com/SharkFourSix/crudiator"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStrictModeRejectsUnexpectedFields(t *testing.T) {
+	db, fdb := newFakeDb(t)
+	editor := newStudentEditor(
+		crudiator.POSTGRESQL,
+		crudiator.NewField("email", crudiator.IncludeOnCreate, crudiator.IncludeOnRead),
+		crudiator.NewField("is_admin", crudiator.IncludeOnRead),
+		deletedAtFilter,
+		schoolFilter,
+	).Strict(true).Build()
+
+	form := crudiator.MapBackedDataForm{"name": "Jane", "email": "jane@example.com", "is_admin": true, "id": 4}
+	_, err := editor.Create(form, db)
+	var unexpected *crudiator.UnexpectedFieldsError
+	require.ErrorAs(t, err, &unexpected)
+	require.Equal(t, crudiator.OpCreate, unexpected.Operation)
+	require.Equal(t, []string{"id", "is_admin"}, unexpected.Fields)
+	require.Empty(t, fdb.all())
+
+	form = crudiator.MapBackedDataForm{"id": 4, "school_id": 1, "name": "Jane", "email": "jane@example.com"}
+	_, err = editor.Update(form, db)
+	require.ErrorAs(t, err, &unexpected)
+	require.Equal(t, []string{"email"}, unexpected.Fields)
+
+	fdb.queueRows([]string{"id"}, []driver.Value{int64(4)})
+	delete(form, "email")
+	_, err = editor.Update(form, db)
+	require.NoError(t, err)
+}
+
+func TestSanitizeForm(t *testing.T) {
+	editor := newStudentEditor(
+		crudiator.POSTGRESQL,
+		crudiator.NewField("email", crudiator.IncludeOnCreate, crudiator.IncludeOnRead),
+		crudiator.NewField("is_admin", crudiator.IncludeOnRead),
+		deletedAtFilter,
+		schoolFilter,
+	).Strict(true).Build()
+
+	form := crudiator.MapBackedDataForm{"id": 4, "school_id": 1, "name": "Jane", "email": "jane@example.com", "is_admin": true, "deleted_at": nil}
+	editor.SanitizeForm(crudiator.OpUpdate, form)
+	require.Equal(t, crudiator.MapBackedDataForm{"id": 4, "school_id": 1, "name": "Jane"}, form)
+
+	form = crudiator.MapBackedDataForm{"id": 4, "school_id": 1, "name": "Jane"}
+	editor.SanitizeForm(crudiator.OpCreate, form)
+	require.Equal(t, crudiator.MapBackedDataForm{"name": "Jane"}, form)
+}
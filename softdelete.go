@@ -0,0 +1,428 @@
+package crudiator
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// CascadeAction is applied to the rows of a 'HasMany()' relation when the row they depend on is
+// soft deleted
+type CascadeAction int
+
+const (
+	// Soft deletes the dependent rows along with the row, and restores them with the row
+	CascadeSoftDelete CascadeAction = iota + 1
+	// Sets the foreign key of the dependent rows to NULL. Restoring the row does not link them
+	// back to it
+	CascadeNullify
+)
+
+// Returned by Restore when the editor does not soft delete rows
+var ErrNotSoftDeleted = errors.New("rows are not soft deleted")
+
+// OnSoftDelete declares the action applied, in the same transaction, to the rows of the 'HasMany()'
+// relation when 'Delete()' soft deletes the row they depend on.
+func (e *Editor) OnSoftDelete(relation string, action CascadeAction) *Editor {
+	for i, r := range e.relations {
+		if r.name == relation {
+			if r.kind != HasManyRelation {
+				panic(errors.Errorf("relation '%s' is not a has-many relation", relation))
+			}
+			e.relations[i].cascade = action
+			return e
+		}
+	}
+	panic(errors.Errorf("unknown relation '%s'", relation))
+}
+
+// Returns whether soft deleting, or restoring, rows updates dependent rows
+func (e Editor) cascades(restore bool) bool {
+	for _, r := range e.relations {
+		if r.cascade == CascadeSoftDelete || (r.cascade == CascadeNullify && !restore) {
+			return true
+		}
+	}
+	return false
+}
+
+// Restore resets the soft deletion fields of a soft deleted row, and of the rows soft deleted
+// along with it through 'OnSoftDelete()'. Restore is authorized as OpDelete.
+func (e Editor) Restore(form DataForm, db *sql.DB) (DbRow, error) {
+	return e.RestoreContext(context.Background(), form, db)
+}
+
+func (e Editor) RestoreContext(ctx context.Context, form DataForm, db *sql.DB) (DbRow, error) {
+	e = e.forRole(ctx)
+	if err := e.checkOperation(OpDelete); err != nil {
+		return nil, err
+	}
+	if !e.softDelete {
+		return nil, ErrNotSoftDeleted
+	}
+	if e.cascades(true) {
+		var row DbRow
+		err := e.inTransaction(ctx, db, func(tx *sql.Tx) (err error) {
+			row, err = e.restore(ctx, form, tx)
+			return err
+		})
+		return row, err
+	}
+	return e.restore(ctx, form, db)
+}
+
+func (e Editor) restore(ctx context.Context, form DataForm, q queryer) (DbRow, error) {
+	var row DbRow
+	var affected bool
+	predicates, err := e.authorize(ctx, OpDelete, form)
+	if err != nil {
+		return nil, err
+	}
+	pk, err := e.getFieldvalue(e.primaryKeyField, form)
+	if err != nil {
+		return nil, err
+	}
+	filterValues, err := e.getFieldValues(ctx, e.restoreFilterFields(), form)
+	if err != nil {
+		return nil, err
+	}
+	var at time.Time
+	if e.cascades(true) {
+		// the dependent rows soft deleted along with the row carry the same time
+		if at, err = e.deletionTime(ctx, q, append([]any{pk}, filterValues...), predicates); err != nil {
+			return nil, err
+		}
+	}
+
+	values := e.getRestoredValues()
+	values = append(values, pk)
+	values = append(values, filterValues...)
+	versionValues, err := e.getVersionValues(form)
+	if err != nil {
+		return nil, err
+	}
+	values = append(values, versionValues...)
+	query, values := e.restoreStatement.with(e.dialect, values, predicates)
+
+	switch e.dialect {
+	case SQLITE:
+		fallthrough
+	case MYSQL:
+		res, err := q.ExecContext(ctx, query, values...)
+		if err != nil {
+			return nil, err
+		}
+		if err := e.checkAffected(res); err != nil {
+			return nil, err
+		}
+		if affected, err = rowsAffected(res); err != nil {
+			return nil, err
+		}
+		if row, err = e.singleRead(ctx, form, q, predicates); err != nil {
+			return nil, err
+		}
+	case POSTGRESQL:
+		rows, err := q.QueryContext(ctx, query, values...)
+		if err != nil {
+			return nil, err
+		}
+		if row, affected, err = e.scanAffectedRow(rows); err != nil {
+			return nil, err
+		}
+	}
+
+	if affected {
+		if err := e.cascade(ctx, q, form, at, true); err != nil {
+			return nil, err
+		}
+	}
+	return row, nil
+}
+
+// Returns the values of the soft deletion fields of a row that is not deleted
+func (e Editor) getRestoredValues() []any {
+	var data []any
+	for _, f := range e.fields {
+		if f.SoftDelete {
+			switch f.SoftDeleteType {
+			case TimestampField:
+				data = append(data, nil)
+			case BoolField:
+				data = append(data, false)
+			default:
+				data = append(data, 0)
+			}
+		}
+	}
+	return data
+}
+
+// Returns the selection filters which do not filter on soft deletion fields
+func (e Editor) restoreFilterFields() []string {
+	var filters []string
+	for _, f := range e.fields {
+		if !f.SelectionFilter || f.SoftDelete {
+			continue
+		}
+		switch f.NullCheck {
+		case FieldMustBeNull:
+			filters = append(filters, e.fieldExpression(f, false)+" IS NULL")
+		case FieldMustNotBeNull:
+			filters = append(filters, e.fieldExpression(f, false)+" IS NOT NULL")
+		default:
+			filters = append(filters, e.fieldExpression(f, false))
+		}
+	}
+	return filters
+}
+
+func (e *Editor) buildRestoreStatement() *Editor {
+	var builder strings.Builder
+	if !e.softDelete {
+		e.restoreStatement = statement{}
+		return e
+	}
+
+	builder.WriteString("UPDATE ")
+	builder.WriteString(e.tableNameQuoted)
+	builder.WriteString(" SET ")
+	builder.WriteString(ParameterizeFields(e.softDeleteColumns, e.dialect, false))
+	if e.versionField != "" {
+		builder.WriteRune(',')
+		builder.WriteString(e.versionIncrement())
+	}
+	count := len(e.softDeleteColumns) + 1
+	builder.WriteString(" WHERE ")
+	builder.WriteString(e.quote(e.primaryKeyField))
+	builder.WriteRune('=')
+	builder.WriteString(placeholder(e.dialect, count))
+
+	var filters []string
+	for _, f := range e.fields {
+		if !f.SelectionFilter || f.SoftDelete {
+			continue
+		}
+		switch f.NullCheck {
+		case FieldMustBeNull:
+			filters = append(filters, e.fieldExpression(f, false)+" IS NULL")
+		case FieldMustNotBeNull:
+			filters = append(filters, e.fieldExpression(f, false)+" IS NOT NULL")
+		default:
+			count++
+			filters = append(filters, e.fieldExpression(f, false)+"="+placeholder(e.dialect, count))
+		}
+	}
+	if len(filters) > 0 {
+		builder.WriteString(" AND (")
+		builder.WriteString(strings.Join(filters, " AND "))
+		builder.WriteRune(')')
+	}
+	if e.versionField != "" {
+		count++
+		builder.WriteString(" AND ")
+		builder.WriteString(e.quote(e.versionField))
+		builder.WriteRune('=')
+		builder.WriteString(placeholder(e.dialect, count))
+	}
+
+	e.restoreStatement = statement{at: builder.Len(), where: true, bound: count}
+	if e.dialect == POSTGRESQL {
+		builder.WriteString(" RETURNING ")
+		builder.WriteString(strings.Join(e.readFields, ","))
+	}
+	e.restoreStatement.sql = builder.String()
+	e.logger.Debug("restore statement => %s", e.restoreStatement.sql)
+	return e
+}
+
+// Applies the cascades of the relations to the rows depending on the row of the form, which was
+// soft deleted, or restored, at the given time
+func (e Editor) cascade(ctx context.Context, q queryer, form DataForm, at time.Time, restore bool) error {
+	if !e.cascades(restore) {
+		return nil
+	}
+	pk, err := e.getFieldvalue(e.primaryKeyField, form)
+	if err != nil {
+		return err
+	}
+	return e.cascadeTo(ctx, q, []any{pk}, at, restore)
+}
+
+// Applies the cascades of the relations to the rows depending on the rows with the given keys,
+// one level at a time. A row is updated once at most, so cycles end the walk.
+func (e Editor) cascadeTo(ctx context.Context, q queryer, keys []any, at time.Time, restore bool) error {
+	for _, r := range e.relations {
+		if r.cascade == 0 || (restore && r.cascade != CascadeSoftDelete) {
+			continue
+		}
+		child := *r.editor
+		if r.cascade == CascadeSoftDelete && len(child.softDeleteColumns) == 0 {
+			return errors.Errorf("relation '%s': editor has no soft deletion fields", r.name)
+		}
+
+		var dependents []any
+		for start := 0; start < len(keys); start += relationBatchSize {
+			batch := keys[start:min(start+relationBatchSize, len(keys))]
+			where, err := child.dependentRows(ctx, r.foreignKey, batch, at, r.cascade == CascadeSoftDelete, restore)
+			if err != nil {
+				return errors.Wrapf(err, "relation '%s'", r.name)
+			}
+			if r.cascade == CascadeNullify {
+				if err := child.cascadeUpdate(ctx, q, []string{child.quote(r.foreignKey) + "=NULL"}, nil, where); err != nil {
+					return errors.Wrapf(err, "relation '%s'", r.name)
+				}
+				continue
+			}
+
+			selected, err := child.dependentKeys(ctx, q, where)
+			if err != nil {
+				return errors.Wrapf(err, "relation '%s'", r.name)
+			}
+			if len(selected) == 0 {
+				continue
+			}
+			var set []string
+			for _, c := range child.softDeleteColumns {
+				set = append(set, c+"=?")
+			}
+			args := child.getSoftDeletionValues(at)
+			if restore {
+				args = child.getRestoredValues()
+			}
+			in := strings.TrimSuffix(strings.Repeat("?,", len(selected)), ",")
+			where = Where(child.quote(child.primaryKeyField)+" IN ("+in+") AND "+where.SQL, append(selected, where.Args...)...)
+			if err := child.cascadeUpdate(ctx, q, set, args, where); err != nil {
+				return errors.Wrapf(err, "relation '%s'", r.name)
+			}
+			dependents = append(dependents, selected...)
+		}
+
+		if len(dependents) > 0 {
+			if err := child.cascadeTo(ctx, q, dependents, at, restore); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Reads the primary keys of the rows satisfying the condition
+func (e Editor) dependentKeys(ctx context.Context, q queryer, where Predicate) ([]any, error) {
+	query := bindPlaceholders(e.dialect, "SELECT "+e.quote(e.primaryKeyField)+" FROM "+e.tableNameQuoted+" WHERE "+where.SQL)
+	e.logger.Debug("cascade statement => %s", query)
+	rows, err := q.QueryContext(ctx, query, where.Args...)
+	if err != nil {
+		return nil, err
+	}
+	values, err := scanValues(rows, 1)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]any, len(values))
+	for i, v := range values {
+		keys[i] = v[0]
+	}
+	return keys, nil
+}
+
+// Updates the rows satisfying the condition with the assignments, followed by the increment of
+// the version field, if any
+func (e Editor) cascadeUpdate(ctx context.Context, q queryer, set []string, args []any, where Predicate) error {
+	if e.versionField != "" {
+		set = append(set, e.versionIncrement())
+	}
+	query := "UPDATE " + e.tableNameQuoted + " SET " + strings.Join(set, ",") + " WHERE " + where.SQL
+	query = bindPlaceholders(e.dialect, query)
+	e.logger.Debug("cascade statement => %s", query)
+	_, err := q.ExecContext(ctx, query, append(args, where.Args...)...)
+	return err
+}
+
+// Returns the condition selecting the rows whose foreign key is one of keys, and which are not
+// soft deleted yet or, when restoring, were soft deleted at the given time.
+func (e Editor) dependentRows(ctx context.Context, foreignKey string, keys []any, at time.Time, softDelete bool, restore bool) (Predicate, error) {
+	in := strings.TrimSuffix(strings.Repeat("?,", len(keys)), ",")
+	conditions := []string{e.quote(foreignKey) + " IN (" + in + ")"}
+	args := append([]any{}, keys...)
+	for _, f := range e.fields {
+		if !f.SelectionFilter || (softDelete && f.SoftDelete) {
+			continue
+		}
+		switch {
+		case f.NullCheck == FieldMustBeNull:
+			conditions = append(conditions, e.fieldExpression(f, false)+" IS NULL")
+		case f.NullCheck == FieldMustNotBeNull:
+			conditions = append(conditions, e.fieldExpression(f, false)+" IS NOT NULL")
+		case f.Name == e.tenantField:
+			tenant, err := e.tenantValue(ctx)
+			if err != nil {
+				return Predicate{}, err
+			}
+			conditions = append(conditions, e.quote(f.Name)+"=?")
+			args = append(args, tenant)
+		}
+	}
+	if !softDelete {
+		return Where(strings.Join(conditions, " AND "), args...), nil
+	}
+
+	restored := e.getRestoredValues()
+	i := 0
+	for _, f := range e.fields {
+		if !f.SoftDelete {
+			continue
+		}
+		column := e.quote(f.Name)
+		switch {
+		case !restore && restored[i] == nil:
+			conditions = append(conditions, column+" IS NULL")
+		case !restore:
+			conditions = append(conditions, column+"=?")
+			args = append(args, restored[i])
+		case f.SoftDeleteType == TimestampField && !at.IsZero():
+			conditions = append(conditions, column+"=?")
+			args = append(args, at)
+		case restored[i] == nil:
+			conditions = append(conditions, column+" IS NOT NULL")
+		default:
+			conditions = append(conditions, column+"<>?")
+			args = append(args, restored[i])
+		}
+		i++
+	}
+	return Where(strings.Join(conditions, " AND "), args...), nil
+}
+
+// Reads the time at which the row was soft deleted, or the zero time if it has no timestamp
+// soft deletion field or is not found.
+func (e Editor) deletionTime(ctx context.Context, q queryer, values []any, predicates []Predicate) (time.Time, error) {
+	for _, f := range e.fields {
+		if !f.SoftDelete || f.SoftDeleteType != TimestampField {
+			continue
+		}
+		conditions := []string{e.quote(e.primaryKeyField) + "=?"}
+		for _, filter := range e.restoreFilterFields() {
+			if e.fieldHasNullConstraint(filter) {
+				conditions = append(conditions, filter)
+			} else {
+				conditions = append(conditions, filter+"=?")
+			}
+		}
+		sql := bindPlaceholders(e.dialect, "SELECT "+e.quote(f.Name)+" FROM "+e.tableNameQuoted+" WHERE "+strings.Join(conditions, " AND "))
+		query, args := statement{sql: sql, at: len(sql), where: true, bound: len(values)}.with(e.dialect, values, predicates)
+		e.logger.Debug("deletion time statement => %s", query)
+		rows, err := q.QueryContext(ctx, query, args...)
+		if err != nil {
+			return time.Time{}, err
+		}
+		values, err := scanValues(rows, 1)
+		if err != nil || len(values) == 0 || values[0][0] == nil {
+			return time.Time{}, err
+		}
+		return asTime(values[0][0])
+	}
+	return time.Time{}, nil
+}
@@ -0,0 +1,188 @@
+package crudiator_test
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/SharkFourSix/crudiator"
+	"github.com/stretchr/testify/require"
+)
+
+func newCascadingEditors(now time.Time, policies ...crudiator.Policy) crudiator.Crudiator {
+	softDeleted := func(table string, fields ...crudiator.Field) *crudiator.Editor {
+		fields = append([]crudiator.Field{
+			crudiator.NewField("id", crudiator.IsPrimaryKey, crudiator.IncludeOnRead),
+		}, fields...)
+		fields = append(fields, crudiator.NewField("deleted_at", crudiator.IncludeOnRead, crudiator.IsSelectionFilter,
+			crudiator.IsNullConstant, crudiator.SoftDeleteAs(crudiator.TimestampField)))
+		return crudiator.MustNewEditor(table, crudiator.POSTGRESQL, fields...).
+			SoftDelete(true).
+			SetClock(func() time.Time { return now })
+	}
+	schools := softDeleted("schools", crudiator.NewField("name", crudiator.IncludeAlways))
+	students := softDeleted("students", crudiator.NewField("school_id", crudiator.IncludeAlways))
+	grades := softDeleted("grades", crudiator.NewField("student_id", crudiator.IncludeAlways))
+	visits := crudiator.MustNewEditor(
+		"visits",
+		crudiator.POSTGRESQL,
+		crudiator.NewField("id", crudiator.IsPrimaryKey, crudiator.IncludeOnRead),
+		crudiator.NewField("school_id", crudiator.IncludeAlways),
+	)
+
+	schools.HasMany("students", students, "school_id").
+		HasMany("visits", visits, "school_id").
+		OnSoftDelete("students", crudiator.CascadeSoftDelete).
+		OnSoftDelete("visits", crudiator.CascadeNullify)
+	students.HasMany("grades", grades, "student_id").
+		OnSoftDelete("grades", crudiator.CascadeSoftDelete)
+	for _, p := range policies {
+		schools.AddPolicy(p)
+	}
+	students.Build()
+	grades.Build()
+	visits.Build()
+	return schools.Build()
+}
+
+func TestCascadingSoftDelete(t *testing.T) {
+	db, fdb := newFakeDb(t)
+	now := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	schools := newCascadingEditors(now)
+
+	fdb.queueRows([]string{"id", "name", "deleted_at"}, []driver.Value{int64(1), "Chichiri", now})
+	fdb.queueRows([]string{"id"}, []driver.Value{int64(10)}, []driver.Value{int64(11)})
+	fdb.queue(fakeResult{RowsAffected: 2})
+	fdb.queueRows([]string{"id"}, []driver.Value{int64(100)})
+	fdb.queue(fakeResult{RowsAffected: 1})
+	fdb.queue(fakeResult{RowsAffected: 1})
+	_, err := schools.Delete(crudiator.MapBackedDataForm{"id": 1}, db)
+	require.NoError(t, err)
+
+	// each level is selected, then updated with the time of the school
+	queries := fdb.all()
+	require.Len(t, queries, 6)
+	require.Equal(t, `SELECT "id" FROM "students" WHERE "school_id" IN ($1) AND "deleted_at" IS NULL`, queries[1].Query)
+	require.Equal(t, `UPDATE "students" SET "deleted_at"=$1 WHERE "id" IN ($2,$3) AND "school_id" IN ($4) AND "deleted_at" IS NULL`, queries[2].Query)
+	require.Equal(t, []any{now, int64(10), int64(11), 1}, queries[2].Args)
+	require.Equal(t, `SELECT "id" FROM "grades" WHERE "student_id" IN ($1,$2) AND "deleted_at" IS NULL`, queries[3].Query)
+	require.Equal(t, []any{now, int64(100), int64(10), int64(11)}, queries[4].Args)
+	require.Equal(t, `UPDATE "visits" SET "school_id"=NULL WHERE "school_id" IN ($1)`, queries[5].Query)
+	require.Equal(t, []string{"commit"}, fdb.txEnds)
+}
+
+func TestSelfReferencingCascade(t *testing.T) {
+	db, fdb := newFakeDb(t)
+	now := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	categories := crudiator.MustNewEditor(
+		"categories",
+		crudiator.MYSQL,
+		crudiator.NewField("id", crudiator.IsPrimaryKey, crudiator.IncludeOnRead),
+		crudiator.NewField("parent_id", crudiator.IncludeAlways),
+		crudiator.NewField("deleted_at", crudiator.IncludeOnRead, crudiator.SoftDeleteAs(crudiator.TimestampField)),
+	).SoftDelete(true).SetClock(func() time.Time { return now })
+	categories.HasMany("children", categories, "parent_id").
+		OnSoftDelete("children", crudiator.CascadeSoftDelete)
+	editor := categories.Build()
+
+	// 1 <- 2 <- 3, then nothing left to soft delete
+	fdb.queue(oneRowAffected)
+	fdb.queueRows([]string{"id", "parent_id", "deleted_at"}, []driver.Value{int64(1), nil, now})
+	fdb.queueRows([]string{"id"}, []driver.Value{int64(2)})
+	fdb.queue(oneRowAffected)
+	fdb.queueRows([]string{"id"}, []driver.Value{int64(3)})
+	fdb.queue(oneRowAffected)
+	fdb.queueRows([]string{"id"})
+	_, err := editor.Delete(crudiator.MapBackedDataForm{"id": 1}, db)
+	require.NoError(t, err)
+
+	queries := fdb.all()
+	require.Len(t, queries, 7)
+	for _, q := range queries {
+		// MySQL cannot update a table selected by a subquery of the update
+		require.NotContains(t, q.Query, "(SELECT")
+	}
+	// rows already soft deleted are never selected again, which ends cycles
+	require.Equal(t, "SELECT `id` FROM `categories` WHERE `parent_id` IN (?) AND `deleted_at` IS NULL", queries[2].Query)
+	require.Equal(t, "UPDATE `categories` SET `deleted_at`=? WHERE `id` IN (?) AND `parent_id` IN (?) AND `deleted_at` IS NULL", queries[3].Query)
+	require.Equal(t, []any{now, int64(2), 1}, queries[3].Args)
+	require.Equal(t, []any{int64(2)}, queries[4].Args)
+	require.Equal(t, []any{int64(3)}, queries[6].Args)
+	require.Equal(t, []string{"commit"}, fdb.txEnds)
+}
+
+func TestRestore(t *testing.T) {
+	db, fdb := newFakeDb(t)
+	deleted := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	schools := newCascadingEditors(time.Now())
+
+	fdb.queueRows([]string{"deleted_at"}, []driver.Value{deleted})
+	fdb.queueRows([]string{"id", "name", "deleted_at"}, []driver.Value{int64(1), "Chichiri", nil})
+	fdb.queueRows([]string{"id"}, []driver.Value{int64(10)})
+	fdb.queue(oneRowAffected)
+	fdb.queueRows([]string{"id"})
+	_, err := schools.Restore(crudiator.MapBackedDataForm{"id": 1}, db)
+	require.NoError(t, err)
+
+	queries := fdb.all()
+	require.Len(t, queries, 5)
+	require.Equal(t, `SELECT "deleted_at" FROM "schools" WHERE "id"=$1`, queries[0].Query)
+	require.Equal(t, `UPDATE "schools" SET "deleted_at"=$1 WHERE "id"=$2 RETURNING "id","name","deleted_at"`, queries[1].Query)
+	require.Equal(t, []any{nil, 1}, queries[1].Args)
+	// only the students soft deleted along with the school are restored
+	require.Equal(t, `SELECT "id" FROM "students" WHERE "school_id" IN ($1) AND "deleted_at"=$2`, queries[2].Query)
+	require.Equal(t, []any{1, deleted}, queries[2].Args)
+	require.Equal(t, `UPDATE "students" SET "deleted_at"=$1 WHERE "id" IN ($2) AND "school_id" IN ($3) AND "deleted_at"=$4`, queries[3].Query)
+	require.Equal(t, []any{nil, int64(10), 1, deleted}, queries[3].Args)
+	require.Equal(t, `SELECT "id" FROM "grades" WHERE "student_id" IN ($1) AND "deleted_at"=$2`, queries[4].Query)
+
+	_, err = crudiator.MustNewEditor("visits", crudiator.SQLITE,
+		crudiator.NewField("id", crudiator.IsPrimaryKey, crudiator.IncludeOnRead),
+	).Build().Restore(crudiator.MapBackedDataForm{"id": 1}, db)
+	require.ErrorIs(t, err, crudiator.ErrNotSoftDeleted)
+}
+
+func TestCascadeOfExcludedRow(t *testing.T) {
+	db, fdb := newFakeDb(t)
+	deleted := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	schools := newCascadingEditors(deleted, crudiator.PolicyFunc(
+		func(ctx context.Context, op crudiator.Operation, form crudiator.DataForm) ([]crudiator.Predicate, error) {
+			return []crudiator.Predicate{crudiator.Where(`"owner"=?`, "jane")}, nil
+		},
+	))
+
+	// the policy excludes the school, so its dependent rows are left untouched
+	fdb.queueRows([]string{"id", "name", "deleted_at"})
+	_, err := schools.Delete(crudiator.MapBackedDataForm{"id": 1}, db)
+	require.NoError(t, err)
+	require.Len(t, fdb.all(), 1)
+	require.Equal(t, `UPDATE "schools" SET "deleted_at"=$1 WHERE "id"=$2 AND ("deleted_at" IS NULL) AND ("owner"=$3) RETURNING "id","name","deleted_at"`, fdb.last().Query)
+
+	fdb.queueRows([]string{"deleted_at"}, []driver.Value{deleted})
+	fdb.queueRows([]string{"id", "name", "deleted_at"})
+	_, err = schools.Restore(crudiator.MapBackedDataForm{"id": 1}, db)
+	require.NoError(t, err)
+	queries := fdb.all()[1:]
+	require.Len(t, queries, 2)
+	require.Equal(t, `SELECT "deleted_at" FROM "schools" WHERE "id"=$1 AND ("owner"=$2)`, queries[0].Query)
+	require.Equal(t, []any{1, "jane"}, queries[0].Args)
+}
+
+func TestRestoreWithNullFilter(t *testing.T) {
+	db, fdb := newFakeDb(t)
+	editor := crudiator.MustNewEditor(
+		"students",
+		crudiator.POSTGRESQL,
+		crudiator.NewField("id", crudiator.IsPrimaryKey, crudiator.IncludeOnRead),
+		crudiator.NewField("archived_at", crudiator.IsSelectionFilter, crudiator.IsNullConstant),
+		crudiator.NewField("deleted_at", crudiator.IncludeOnRead, crudiator.IsSelectionFilter,
+			crudiator.IsNullConstant, crudiator.SoftDeleteAs(crudiator.TimestampField)),
+	).SoftDelete(true).Build()
+
+	fdb.queueRows([]string{"id", "deleted_at"}, []driver.Value{int64(1), nil})
+	_, err := editor.Restore(crudiator.MapBackedDataForm{"id": 1}, db)
+	require.NoError(t, err)
+	require.Equal(t, `UPDATE "students" SET "deleted_at"=$1 WHERE "id"=$2 AND ("archived_at" IS NULL) RETURNING "id","deleted_at"`, fdb.last().Query)
+	require.Equal(t, []any{nil, 1}, fdb.last().Args)
+}
@@ -4,6 +4,8 @@ import (
 	"database/sql"
 	"strconv"
 	"strings"
+
+	"github.com/pkg/errors"
 )
 
 // Represents a database row (column set)
@@ -57,6 +59,123 @@ func (row DbRow) Scan(rows *sql.Rows) error {
 	return nil
 }
 
+// RowScanner scans the rows of a single result set into DbRow instances, reusing its scan buffers
+type RowScanner struct {
+	columns  []string
+	values   []any
+	pointers []any
+	targets  []FieldType    // normalization target per column, nil when not normalizing
+	decoders []valueDecoder // conversion applied per column after normalization, nil when none
+	omitted  []bool         // columns left out of the rows, nil when none
+}
+
+// Converts a scanned value
+type valueDecoder func(value any) (any, error)
+
+// Sets the conversions applied to the columns, keyed by column name, and the columns left out
+// of the rows
+func (s *RowScanner) decode(decoders map[string]valueDecoder, omitted map[string]bool) {
+	if len(decoders) > 0 {
+		s.decoders = make([]valueDecoder, len(s.columns))
+		for index, col := range s.columns {
+			s.decoders[index] = decoders[col]
+		}
+	}
+	if len(omitted) > 0 {
+		s.omitted = make([]bool, len(s.columns))
+		for index, col := range s.columns {
+			s.omitted[index] = omitted[col]
+		}
+	}
+}
+
+// Creates a scanner for the given result set
+func NewRowScanner(rows *sql.Rows) (*RowScanner, error) {
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	s := &RowScanner{
+		columns:  cols,
+		values:   make([]any, len(cols)),
+		pointers: make([]any, len(cols)),
+	}
+	for index := range s.pointers {
+		s.pointers[index] = &s.values[index]
+	}
+	return s, nil
+}
+
+// Creates a scanner that normalizes every scanned value through 'NormalizeValue()', using the
+// type declared for the column or the database type reported by the driver.
+func NewNormalizingRowScanner(rows *sql.Rows, declared map[string]FieldType) (*RowScanner, error) {
+	s, err := NewRowScanner(rows)
+	if err != nil {
+		return nil, err
+	}
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+	s.targets = make([]FieldType, len(s.columns))
+	for index, col := range s.columns {
+		s.targets[index] = UnknownField
+		if t, ok := declared[col]; ok {
+			s.targets[index] = t
+		} else if index < len(types) {
+			s.targets[index] = fieldTypeOf(types[index].DatabaseTypeName())
+		}
+	}
+	return s, nil
+}
+
+// Returns the column names of the result set
+func (s *RowScanner) Columns() []string {
+	return s.columns
+}
+
+// Scans the columns at the current cursor into a new row. sql.Rows.Next() must have already
+// been called before calling this function.
+func (s *RowScanner) Scan(rows *sql.Rows) (DbRow, error) {
+	row := make(DbRow, len(s.columns))
+	if err := s.ScanInto(rows, row); err != nil {
+		return nil, err
+	}
+	return row, nil
+}
+
+// Scans the columns at the current cursor into the given row.
+func (s *RowScanner) ScanInto(rows *sql.Rows, row DbRow) error {
+	// database/sql copies driver owned byte slices when scanning into *any, so the buffers
+	// can safely be reused once their values have been moved into the row
+	if err := rows.Scan(s.pointers...); err != nil {
+		return err
+	}
+	for index, col := range s.columns {
+		value := s.values[index]
+		s.values[index] = nil
+		if s.targets != nil {
+			normalized, err := NormalizeValue(value, s.targets[index])
+			if err != nil {
+				return errors.Wrapf(err, "column '%s'", col)
+			}
+			value = normalized
+		}
+		if s.omitted != nil && s.omitted[index] {
+			continue
+		}
+		if s.decoders != nil && s.decoders[index] != nil {
+			decoded, err := s.decoders[index](value)
+			if err != nil {
+				return errors.Wrapf(err, "column '%s'", col)
+			}
+			value = decoded
+		}
+		row[col] = value
+	}
+	return nil
+}
+
 func ParameterizeFields(fields []string, dialect SQLDialect, useAnd bool, startCountFrom ...int) string {
 	var separator bool
 	var builder strings.Builder
@@ -99,6 +218,123 @@ func ParameterizeFields(fields []string, dialect SQLDialect, useAnd bool, startC
 	return builder.String()
 }
 
+// Returns the n-th (one based) parameter placeholder of the dialect
+func placeholder(dialect SQLDialect, n int) string {
+	if dialect == POSTGRESQL {
+		return "$" + strconv.Itoa(n)
+	}
+	return "?"
+}
+
+// Replaces the '?' placeholders of the query with the placeholders of the dialect
+func bindPlaceholders(dialect SQLDialect, query string) string {
+	var builder strings.Builder
+	for i, part := range splitPlaceholders(dialect, query) {
+		if i > 0 {
+			builder.WriteString(placeholder(dialect, i))
+		}
+		builder.WriteString(part)
+	}
+	return builder.String()
+}
+
+// Splits the query around its '?' placeholders, skipping literals, identifiers and comments.
+// '??' stands for a literal '?'.
+func splitPlaceholders(dialect SQLDialect, query string) []string {
+	var parts []string
+	var builder strings.Builder
+	for i := 0; i < len(query); i++ {
+		if end := skipQuoted(dialect, query, i); end > i {
+			builder.WriteString(query[i:end])
+			i = end - 1
+			continue
+		}
+		if query[i] != '?' {
+			builder.WriteByte(query[i])
+			continue
+		}
+		rest := query[i+1:]
+		switch {
+		case strings.HasPrefix(rest, "?"):
+			builder.WriteByte('?')
+			i++
+		case (strings.HasPrefix(rest, "|") && !strings.HasPrefix(rest, "||")) ||
+			(strings.HasPrefix(rest, "&") && !strings.HasPrefix(rest, "&&")):
+			builder.WriteByte('?')
+		default:
+			parts = append(parts, builder.String())
+			builder.Reset()
+		}
+	}
+	return append(parts, builder.String())
+}
+
+// Returns the number of '?' placeholders of the query. See 'splitPlaceholders()'
+func countPlaceholders(dialect SQLDialect, query string) int {
+	return len(splitPlaceholders(dialect, query)) - 1
+}
+
+// Returns the end of the string literal, quoted identifier or comment starting at the offset
+// of the query, or the offset itself if none starts there
+func skipQuoted(dialect SQLDialect, query string, at int) int {
+	switch c := query[at]; {
+	case c == '\'', c == '"', c == '`':
+		for i := at + 1; i < len(query); i++ {
+			switch {
+			case c != '`' && dialect == MYSQL && query[i] == '\\':
+				// MySQL escapes characters of string literals with backslashes
+				i++
+			case query[i] == c:
+				if i+1 < len(query) && query[i+1] == c {
+					// doubled quote
+					i++
+					continue
+				}
+				return i + 1
+			}
+		}
+		return len(query)
+	case strings.HasPrefix(query[at:], "--"), dialect == MYSQL && c == '#':
+		if end := strings.IndexByte(query[at:], '\n'); end >= 0 {
+			return at + end + 1
+		}
+		return len(query)
+	case strings.HasPrefix(query[at:], "/*"):
+		if end := strings.Index(query[at+2:], "*/"); end >= 0 {
+			return at + 2 + end + 2
+		}
+		return len(query)
+	}
+	return at
+}
+
+// Adds the offset to the numbers of the '$n' placeholders of the PostgreSQL query, leaving string
+// literals, quoted identifiers and comments as is
+func shiftPlaceholders(query string, offset int) string {
+	var builder strings.Builder
+	for i := 0; i < len(query); i++ {
+		if end := skipQuoted(POSTGRESQL, query, i); end > i {
+			builder.WriteString(query[i:end])
+			i = end - 1
+			continue
+		}
+		if query[i] == '$' {
+			end := i + 1
+			for end < len(query) && query[end] >= '0' && query[end] <= '9' {
+				end++
+			}
+			if end > i+1 {
+				n, _ := strconv.Atoi(query[i+1 : end])
+				builder.WriteString("$" + strconv.Itoa(n+offset))
+				i = end - 1
+				continue
+			}
+		}
+		builder.WriteByte(query[i])
+	}
+	return builder.String()
+}
+
 func CreateParameterPlaceholders(count int, dialect SQLDialect) string {
 	var builder strings.Builder
 	var separator bool
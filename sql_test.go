@@ -1,9 +1,14 @@
 package crudiator_test
 
 import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/SharkFourSix/crudiator"
+	"github.com/stretchr/testify/require"
 )
 
 func TestPostgresPlaceholders(t *testing.T) {
@@ -25,3 +30,113 @@ func TestSqliteMysqlPlaceholders(t *testing.T) {
 		t.Fatal("sqlite placeholder generation failed")
 	}
 }
+
+func TestRowScanner(t *testing.T) {
+	db, fdb := newFakeDb(t)
+	fdb.queueRows([]string{"id", "name", "data"},
+		[]driver.Value{int64(1), "a", []byte("one")},
+		[]driver.Value{int64(2), "b", []byte("two")},
+	)
+
+	rows, err := db.Query("SELECT")
+	require.NoError(t, err)
+	defer rows.Close()
+
+	scanner, err := crudiator.NewRowScanner(rows)
+	require.NoError(t, err)
+	require.Equal(t, []string{"id", "name", "data"}, scanner.Columns())
+
+	var scanned []crudiator.DbRow
+	for rows.Next() {
+		row, err := scanner.Scan(rows)
+		require.NoError(t, err)
+		scanned = append(scanned, row)
+	}
+	require.NoError(t, rows.Err())
+	require.Equal(t, []crudiator.DbRow{
+		{"id": int64(1), "name": "a", "data": []byte("one")},
+		{"id": int64(2), "name": "b", "data": []byte("two")},
+	}, scanned)
+}
+
+const (
+	benchmarkRows    = 1000
+	benchmarkColumns = 8
+)
+
+func newBenchmarkDb(b *testing.B) *sql.DB {
+	db, fdb := newFakeDb(b)
+	result := fakeResult{}
+	for c := 0; c < benchmarkColumns; c++ {
+		result.Columns = append(result.Columns, fmt.Sprintf("col%d", c))
+	}
+	now := time.Now()
+	for r := 0; r < benchmarkRows; r++ {
+		row := make([]driver.Value, benchmarkColumns)
+		for c := range row {
+			switch c % 4 {
+			case 0:
+				row[c] = int64(r)
+			case 1:
+				row[c] = "text value"
+			case 2:
+				row[c] = now
+			case 3:
+				row[c] = nil
+			}
+		}
+		result.Rows = append(result.Rows, row)
+	}
+	fdb.repeat = &result
+	return db
+}
+
+// Scans every row with DbRow.Scan, which reads the columns and allocates buffers per row
+func BenchmarkDbRowScan(b *testing.B) {
+	db := newBenchmarkDb(b)
+	rowset := make([]crudiator.DbRow, 0, benchmarkRows)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		rows, err := db.Query("SELECT")
+		if err != nil {
+			b.Fatal(err)
+		}
+		rowset = rowset[:0]
+		for rows.Next() {
+			row := crudiator.DbRow{}
+			if err := row.Scan(rows); err != nil {
+				b.Fatal(err)
+			}
+			rowset = append(rowset, row)
+		}
+		rows.Close()
+	}
+}
+
+// Scans every row with a RowScanner, which reuses the column metadata and buffers
+func BenchmarkRowScanner(b *testing.B) {
+	db := newBenchmarkDb(b)
+	rowset := make([]crudiator.DbRow, 0, benchmarkRows)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		rows, err := db.Query("SELECT")
+		if err != nil {
+			b.Fatal(err)
+		}
+		scanner, err := crudiator.NewRowScanner(rows)
+		if err != nil {
+			b.Fatal(err)
+		}
+		rowset = rowset[:0]
+		for rows.Next() {
+			row, err := scanner.Scan(rows)
+			if err != nil {
+				b.Fatal(err)
+			}
+			rowset = append(rowset, row)
+		}
+		rows.Close()
+	}
+}
@@ -0,0 +1,46 @@
+package crudiator
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+// Returned when the tenant of a tenant scoped editor cannot be found in the context
+var ErrNoTenant = errors.New("no tenant in context")
+
+// TenantScoped scopes every operation of the editor to the tenant returned by tenant for the
+// context of the operation. Operations fail with ErrNoTenant when tenant returns nil.
+func (e *Editor) TenantScoped(column string, tenant func(ctx context.Context) any) *Editor {
+	e.tenantField = column
+	e.tenant = tenant
+
+	provider := func(ctx context.Context, form DataForm) (any, error) {
+		return tenantValue(tenant, ctx)
+	}
+	for i, f := range e.fields {
+		if f.Name == column {
+			f.SelectionFilter = true
+			f.NullCheck = NoFieldNullCheck
+			f.Update = false
+			f.CreateValueProvider = provider
+			f.UpdateValueProvider = nil
+			e.fields[i] = f
+			return e
+		}
+	}
+	e.fields = append(e.fields, NewField(column, IsSelectionFilter, ProvideOnCreate(provider)))
+	return e
+}
+
+// Returns the tenant of the context
+func (e Editor) tenantValue(ctx context.Context) (any, error) {
+	return tenantValue(e.tenant, ctx)
+}
+
+func tenantValue(tenant func(ctx context.Context) any, ctx context.Context) (any, error) {
+	if v := tenant(ctx); v != nil {
+		return v, nil
+	}
+	return nil, ErrNoTenant
+}
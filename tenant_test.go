@@ -0,0 +1,67 @@
+package crudiator_test
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/SharkFourSix/crudiator"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTenantScoped(t *testing.T) {
+	db, fdb := newFakeDb(t)
+	editor := newStudentEditor(crudiator.POSTGRESQL, schoolFilter).TenantScoped("tenant_id", tenantOf).Build()
+	ctx := context.WithValue(context.Background(), tenantKey{}, 9)
+
+	fdb.queueRows([]string{"id"}, []driver.Value{int64(1)})
+	_, err := editor.CreateContext(ctx, crudiator.MapBackedDataForm{"name": "Jane", "tenant_id": 1}, db)
+	require.NoError(t, err)
+	require.Contains(t, fdb.last().Query, `("name","tenant_id") VALUES ($1,$2)`)
+	require.Equal(t, []any{"Jane", 9}, fdb.last().Args)
+
+	fdb.queueRows([]string{"id"})
+	_, err = editor.ReadContext(ctx, crudiator.MapBackedDataForm{"school_id": 3, "tenant_id": 1}, db)
+	require.NoError(t, err)
+	require.Contains(t, fdb.last().Query, `WHERE ("school_id"=$1 AND "tenant_id"=$2)`)
+	require.Equal(t, []any{3, 9}, fdb.last().Args)
+
+	fdb.queueRows([]string{"id"}, []driver.Value{int64(1)})
+	_, err = editor.UpdateContext(ctx, crudiator.MapBackedDataForm{"id": 1, "name": "Jane", "school_id": 3}, db)
+	require.NoError(t, err)
+	require.Contains(t, fdb.last().Query, `SET "name"=$1 WHERE "id"=$2 AND ("school_id"=$3 AND "tenant_id"=$4)`)
+	require.Equal(t, []any{"Jane", 1, 3, 9}, fdb.last().Args)
+
+	fdb.queueRows([]string{"id"}, []driver.Value{int64(1)})
+	_, err = editor.DeleteContext(ctx, crudiator.MapBackedDataForm{"id": 1, "school_id": 3}, db)
+	require.NoError(t, err)
+	require.Equal(t, []any{1, 3, 9}, fdb.last().Args)
+
+	fdb.queueRows([]string{"count"}, []driver.Value{int64(4)})
+	count, err := editor.CountContext(ctx, crudiator.MapBackedDataForm{"school_id": 3}, db)
+	require.NoError(t, err)
+	require.Equal(t, int64(4), count)
+	require.Equal(t, []any{3, 9}, fdb.last().Args)
+
+	executed := len(fdb.all())
+	_, err = editor.Read(crudiator.MapBackedDataForm{"school_id": 3, "tenant_id": 9}, db)
+	require.ErrorIs(t, err, crudiator.ErrNoTenant)
+	_, err = editor.Create(crudiator.MapBackedDataForm{"name": "Jane"}, db)
+	require.ErrorIs(t, err, crudiator.ErrNoTenant)
+	require.Len(t, fdb.all(), executed, "no statement must be executed")
+}
+
+func TestTenantScopedStrict(t *testing.T) {
+	db, fdb := newFakeDb(t)
+	editor := newStudentEditor(crudiator.MYSQL, schoolFilter).TenantScoped("tenant_id", tenantOf).Strict(true).Build()
+	ctx := context.WithValue(context.Background(), tenantKey{}, 9)
+
+	_, err := editor.CreateContext(ctx, crudiator.MapBackedDataForm{"name": "Jane", "tenant_id": 1}, db)
+	var unexpected *crudiator.UnexpectedFieldsError
+	require.ErrorAs(t, err, &unexpected)
+	require.Equal(t, []string{"tenant_id"}, unexpected.Fields)
+
+	form := editor.SanitizeForm(crudiator.OpRead, crudiator.MapBackedDataForm{"school_id": 3, "tenant_id": 1})
+	require.Equal(t, crudiator.MapBackedDataForm{"school_id": 3}, form)
+	require.Empty(t, fdb.all())
+}
@@ -0,0 +1,64 @@
+package crudiator_test
+
+import (
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/SharkFourSix/crudiator"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	createdAtField = crudiator.NewField("created_at", crudiator.IncludeOnRead, crudiator.AutoCreateTimestamp)
+	updatedAtField = crudiator.NewField("updated_at", crudiator.IncludeOnRead, crudiator.AutoCreateTimestamp, crudiator.AutoUpdateTimestamp)
+)
+
+func TestAutoTimestamps(t *testing.T) {
+	db, fdb := newFakeDb(t)
+	now := time.Date(2024, 5, 6, 7, 8, 9, 0, time.FixedZone("CAT", 2*60*60))
+	editor := newStudentEditor(crudiator.POSTGRESQL, createdAtField, updatedAtField).
+		SetClock(func() time.Time { return now }).
+		UTC(true).
+		Build()
+
+	fdb.queueRows([]string{"id"}, []driver.Value{int64(1)})
+	_, err := editor.Create(crudiator.MapBackedDataForm{"name": "Jane", "created_at": "1999-01-01"}, db)
+	require.NoError(t, err)
+	require.Contains(t, fdb.last().Query, `("name","created_at","updated_at") VALUES ($1,$2,$3)`)
+	require.Equal(t, []any{"Jane", now.UTC(), now.UTC()}, fdb.last().Args)
+
+	fdb.queueRows([]string{"id"}, []driver.Value{int64(1)})
+	_, err = editor.Update(crudiator.MapBackedDataForm{"id": 1, "name": "Jane"}, db)
+	require.NoError(t, err)
+	require.Contains(t, fdb.last().Query, `SET "name"=$1,"updated_at"=$2 WHERE "id"=$3`)
+	require.Equal(t, []any{"Jane", now.UTC(), 1}, fdb.last().Args)
+}
+
+func TestAutoTimestampsInSQL(t *testing.T) {
+	db, fdb := newFakeDb(t)
+	editor := newStudentEditor(crudiator.POSTGRESQL, createdAtField, updatedAtField).SQLTimestamps(true).Build()
+
+	fdb.queueRows([]string{"id"}, []driver.Value{int64(1)})
+	_, err := editor.Create(crudiator.MapBackedDataForm{"name": "Jane"}, db)
+	require.NoError(t, err)
+	require.Contains(t, fdb.last().Query, `VALUES ($1,CURRENT_TIMESTAMP,CURRENT_TIMESTAMP)`)
+	require.Equal(t, []any{"Jane"}, fdb.last().Args)
+
+	fdb.queueRows([]string{"id"}, []driver.Value{int64(1)})
+	_, err = editor.Update(crudiator.MapBackedDataForm{"id": 1, "name": "Jane"}, db)
+	require.NoError(t, err)
+	require.Contains(t, fdb.last().Query, `SET "name"=$1,"updated_at"=CURRENT_TIMESTAMP WHERE "id"=$2`)
+	require.Equal(t, []any{"Jane", 1}, fdb.last().Args)
+}
+
+func TestAutoTimestampsRejectedInStrictMode(t *testing.T) {
+	db, fdb := newFakeDb(t)
+	editor := newStudentEditor(crudiator.MYSQL, createdAtField, updatedAtField).Strict(true).Build()
+
+	_, err := editor.Create(crudiator.MapBackedDataForm{"name": "Jane", "created_at": "1999-01-01"}, db)
+	var unexpected *crudiator.UnexpectedFieldsError
+	require.ErrorAs(t, err, &unexpected)
+	require.Equal(t, []string{"created_at"}, unexpected.Fields)
+	require.Empty(t, fdb.all())
+}
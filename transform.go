@@ -0,0 +1,94 @@
+package crudiator
+
+import (
+	"github.com/pkg/errors"
+)
+
+// WriteTransformer converts the value of a field, filters and primary keys included, before it
+// is bound to a statement. A returned error aborts the operation.
+type WriteTransformer func(value any) (any, error)
+
+// ReadTransformer converts the value of a field after it has been scanned from the database,
+// i.e. to mask all but the last digits of a phone number.
+type ReadTransformer func(value any) any
+
+var (
+	// Adds a transformer applied to the value of the field before it is bound to a statement.
+	// Transformers are applied in the order they are declared.
+	OnWrite = func(t WriteTransformer) FieldOption {
+		return func(f *Field) { f.WriteTransformers = append(f.WriteTransformers, t) }
+	}
+
+	// Adds a transformer applied to the value of the field after it is scanned. Transformers are
+	// applied in the order they are declared.
+	OnRead = func(t ReadTransformer) FieldOption {
+		return func(f *Field) { f.ReadTransformers = append(f.ReadTransformers, t) }
+	}
+
+	// Removes the field from every row returned by the editor, so that values such as password
+	// hashes can be written but never leave the editor.
+	RedactOnRead FieldOption = func(f *Field) { f.Redacted = true }
+)
+
+// Applies the write transformers of the field to the value
+func (e Editor) transformWrite(field string, value any) (any, error) {
+	for _, t := range e.fieldsByName[field].WriteTransformers {
+		v, err := t(value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to transform value of '%s'", field)
+		}
+		value = v
+	}
+	return value, nil
+}
+
+// Coerces and transforms the value of the field before it is bound to a statement
+func (e Editor) bindValue(field string, value any) (any, error) {
+	value, err := e.coerceFieldValue(field, value)
+	if err != nil {
+		return nil, err
+	}
+	return e.transformWrite(field, value)
+}
+
+// Computes the conversions applied by the row scanner to the values of each field
+func (e *Editor) buildReadDecoders() *Editor {
+	e.readDecoders = make(map[string]valueDecoder)
+	e.redacted = make(map[string]bool)
+	for _, f := range e.fields {
+		if f.Redacted {
+			e.redacted[f.Name] = true
+			continue
+		}
+		if len(f.ReadTransformers) == 0 && f.Keyring == nil && !f.DecodeJSON {
+			continue
+		}
+		var decrypt valueDecoder
+		if f.Keyring != nil {
+			decrypt = decrypter(f.Keyring)
+		}
+		decodesJSON := f.DecodeJSON
+		transformers := f.ReadTransformers
+		e.readDecoders[f.Name] = func(value any) (any, error) {
+			if decrypt != nil {
+				v, err := decrypt(value)
+				if err != nil {
+					return nil, err
+				}
+				value = v
+			}
+			if decodesJSON {
+				v, err := decodeJSON(value)
+				if err != nil {
+					return nil, err
+				}
+				value = v
+			}
+			for _, t := range transformers {
+				value = t(value)
+			}
+			return value, nil
+		}
+	}
+	return e
+}
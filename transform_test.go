@@ -0,0 +1,60 @@
+package crudiator_test
+
+import (
+	"crypto/sha256"
+	"database/sql/driver"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/SharkFourSix/crudiator"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFieldTransformers(t *testing.T) {
+	db, fdb := newFakeDb(t)
+	lower := func(value any) (any, error) {
+		s, ok := value.(string)
+		if !ok {
+			return nil, errors.New("not a string")
+		}
+		return strings.ToLower(s), nil
+	}
+	hash := func(value any) (any, error) {
+		sum := sha256.Sum256([]byte(value.(string)))
+		return hex.EncodeToString(sum[:]), nil
+	}
+	mask := func(value any) any {
+		s := string(value.([]byte))
+		return strings.Repeat("*", len(s)-2) + s[len(s)-2:]
+	}
+
+	editor := crudiator.MustNewEditor(
+		"users",
+		crudiator.POSTGRESQL,
+		crudiator.NewField("id", crudiator.IsPrimaryKey, crudiator.IncludeOnRead),
+		crudiator.NewField("email", crudiator.IncludeAlways, crudiator.IsSelectionFilter, crudiator.OnWrite(lower)),
+		crudiator.NewField("phone", crudiator.IncludeAlways, crudiator.OnRead(mask)),
+		crudiator.NewField("password_hash", crudiator.IncludeAlways, crudiator.OnWrite(hash), crudiator.RedactOnRead),
+	).Build()
+
+	fdb.queueRows([]string{"id", "email", "phone", "password_hash"},
+		[]driver.Value{int64(1), []byte("jane@example.com"), []byte("0991234567"), []byte("5e88")},
+	)
+	row, err := editor.Create(crudiator.MapBackedDataForm{"email": "Jane@Example.com", "phone": "0991234567", "password_hash": "password"}, db)
+	require.NoError(t, err)
+	require.Equal(t, []any{"jane@example.com", "0991234567", "5e884898da28047151d0e56f8dc6292773603d0d6aabbdd62a11ef721d1542d8"}, fdb.last().Args)
+	require.Equal(t, crudiator.DbRow{"id": int64(1), "email": []byte("jane@example.com"), "phone": "********67"}, row)
+
+	fdb.queueRows([]string{"id", "email", "phone", "password_hash"},
+		[]driver.Value{int64(1), []byte("jane@example.com"), []byte("0991234567"), []byte("5e88")},
+	)
+	rows, err := editor.Read(crudiator.MapBackedDataForm{"email": "JANE@example.com"}, db)
+	require.NoError(t, err)
+	require.Equal(t, []any{"jane@example.com"}, fdb.last().Args)
+	require.NotContains(t, rows[0], "password_hash")
+
+	_, err = editor.Create(crudiator.MapBackedDataForm{"email": 42}, db)
+	require.ErrorContains(t, err, "email")
+}
@@ -0,0 +1,279 @@
+package crudiator
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Returned by the hierarchy queries when the editor has not been configured through 'Hierarchy()'
+var ErrHierarchyNotConfigured = errors.New("hierarchy has not been configured")
+
+const (
+	// Column of the rows returned by hierarchy queries holding their distance from the row the
+	// query starts from
+	DepthColumn = "depth"
+	// Column of the rows returned by hierarchy queries holding the primary keys of the rows
+	// leading to them from the row the query starts from, separated by '/'
+	PathColumn = "path"
+
+	// Separator of the keys of PathColumn
+	pathSeparator = "/"
+)
+
+// Hierarchy declares the column referencing the parent of each row of a self-referencing table,
+// which enables 'Ancestors()', 'Descendants()' and 'Subtree()'. Keys must not contain '/'.
+func (e *Editor) Hierarchy(parentField string) *Editor {
+	e.parentField = parentField
+	return e
+}
+
+func (e *Editor) checkHierarchy() *Editor {
+	if e.parentField == "" {
+		return e
+	}
+	if _, ok := e.fieldsByName[e.parentField]; !ok {
+		panic(errors.Errorf("unknown parent field '%s'", e.parentField))
+	}
+	for _, f := range e.fields {
+		if f.Read && (f.Name == DepthColumn || f.Name == PathColumn) {
+			panic(errors.Errorf("field '%s' conflicts with the columns of hierarchy queries", f.Name))
+		}
+	}
+	return e
+}
+
+type treeDirection int
+
+const (
+	treeUp treeDirection = iota + 1
+	treeDown
+)
+
+// Ancestors reads the ancestors of the row, from its parent (depth 1) to the root
+func (e Editor) Ancestors(form DataForm, db *sql.DB) ([]DbRow, error) {
+	return e.AncestorsContext(context.Background(), form, db)
+}
+
+func (e Editor) AncestorsContext(ctx context.Context, form DataForm, db *sql.DB) ([]DbRow, error) {
+	return e.readTree(ctx, form, db, treeUp, 0, false)
+}
+
+// Descendants reads the descendants of the row down to the given depth, children having depth
+// 1. A depth of 0 reads all descendants. The rows are ordered depth first, siblings by their
+// primary key, compared as numbers when both are integers.
+func (e Editor) Descendants(form DataForm, db *sql.DB, depth int) ([]DbRow, error) {
+	return e.DescendantsContext(context.Background(), form, db, depth)
+}
+
+func (e Editor) DescendantsContext(ctx context.Context, form DataForm, db *sql.DB, depth int) ([]DbRow, error) {
+	return e.readTree(ctx, form, db, treeDown, depth, false)
+}
+
+// Subtree reads the row (depth 0) along with all its descendants, ordered depth first, i.e. each
+// row is followed by its descendants. See 'Descendants()' for the order of siblings.
+func (e Editor) Subtree(form DataForm, db *sql.DB) ([]DbRow, error) {
+	return e.SubtreeContext(context.Background(), form, db)
+}
+
+func (e Editor) SubtreeContext(ctx context.Context, form DataForm, db *sql.DB) ([]DbRow, error) {
+	return e.readTree(ctx, form, db, treeDown, 0, true)
+}
+
+func (e Editor) readTree(ctx context.Context, form DataForm, db *sql.DB, direction treeDirection, depth int, withRoot bool) ([]DbRow, error) {
+	e = e.forRole(ctx)
+	if err := e.checkOperation(OpRead); err != nil {
+		return nil, err
+	}
+	if e.parentField == "" {
+		return nil, ErrHierarchyNotConfigured
+	}
+
+	e.invokePreActionCallback(e.preRead, form)
+	predicates, err := e.authorize(ctx, OpRead, form)
+	if err != nil {
+		return nil, err
+	}
+	pk, err := e.getFieldvalue(e.primaryKeyField, form)
+	if err != nil {
+		return nil, err
+	}
+	filterValues, err := e.getFieldValues(ctx, e.filterFields, form)
+	if err != nil {
+		return nil, err
+	}
+
+	query, args := e.treeStatement(direction, depth, withRoot, predicates)
+	values := []any{pk}
+	values = append(values, filterValues...)
+	values = append(values, args...)
+	values = append(values, filterValues...)
+	values = append(values, args...)
+	if depth > 0 {
+		values = append(values, depth)
+	}
+	e.logger.Debug("hierarchy statement => %s", query)
+
+	rows, err := db.QueryContext(ctx, query, values...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	results, err := e.scanRows(rows)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range results {
+		if path, ok := row[PathColumn].([]byte); ok {
+			row[PathColumn] = string(path)
+		}
+	}
+	if direction == treeDown {
+		sortDepthFirst(results)
+	}
+	if err := e.loadIncluded(ctx, db, results); err != nil {
+		return nil, err
+	}
+	e.invokePostActionCallback(e.postRead, results)
+	return results, nil
+}
+
+// Returns the WITH RECURSIVE statement reading the hierarchy, followed by the arguments of the
+// predicates
+func (e Editor) treeStatement(direction treeDirection, depth int, withRoot bool, predicates []Predicate) (string, []any) {
+	var builder strings.Builder
+	var args []any
+
+	tree := e.quote("__tree")
+	node := tree + "." + e.quote("__node")
+	var conditions []string
+	for _, f := range e.filterFields {
+		if strings.HasSuffix(f, "NULL") {
+			conditions = append(conditions, f)
+		} else {
+			conditions = append(conditions, f+"=?")
+		}
+	}
+	for _, p := range predicates {
+		conditions = append(conditions, "("+p.SQL+")")
+		args = append(args, p.Args...)
+	}
+
+	builder.WriteString("WITH RECURSIVE ")
+	builder.WriteString(tree)
+	builder.WriteString("(" + e.quote("__node") + "," + e.quote("__parent") + "," + e.quote("__depth") + "," + e.quote("__path") + ") AS (")
+
+	// the row the hierarchy starts from
+	builder.WriteString("SELECT ")
+	builder.WriteString(e.quote(e.primaryKeyField) + "," + e.quote(e.parentField) + ",0," + e.castText(e.quote(e.primaryKeyField)))
+	builder.WriteString(" FROM " + e.tableNameQuoted)
+	builder.WriteString(" WHERE ")
+	builder.WriteString(strings.Join(append([]string{e.quote(e.primaryKeyField) + "=?"}, conditions...), " AND "))
+
+	// the rows above or below it
+	builder.WriteString(" UNION ALL SELECT ")
+	builder.WriteString(e.qualify(e.primaryKeyField) + "," + e.qualify(e.parentField) + ",")
+	builder.WriteString(tree + "." + e.quote("__depth") + "+1,")
+	builder.WriteString(e.concat(tree+"."+e.quote("__path"), "'"+pathSeparator+"'", e.castText(e.qualify(e.primaryKeyField))))
+	builder.WriteString(" FROM " + e.tableNameQuoted + " JOIN " + tree + " ON ")
+	if direction == treeUp {
+		builder.WriteString(e.qualify(e.primaryKeyField) + "=" + tree + "." + e.quote("__parent"))
+	} else {
+		builder.WriteString(e.qualify(e.parentField) + "=" + node)
+	}
+	if depth > 0 {
+		conditions = append(conditions, tree+"."+e.quote("__depth")+"<?")
+	}
+	// the row must not be on the path leading to it already
+	conditions = append(conditions, e.position(
+		e.concat("'"+pathSeparator+"'", tree+"."+e.quote("__path"), "'"+pathSeparator+"'"),
+		e.concat("'"+pathSeparator+"'", e.castText(e.qualify(e.primaryKeyField)), "'"+pathSeparator+"'"),
+	)+"=0")
+	if len(conditions) > 0 {
+		builder.WriteString(" WHERE ")
+		builder.WriteString(strings.Join(conditions, " AND "))
+	}
+	builder.WriteString(")")
+
+	builder.WriteString(" SELECT ")
+	for _, f := range e.readFields {
+		builder.WriteString(e.qualify(e.unquote(f)) + ",")
+	}
+	builder.WriteString(tree + "." + e.quote("__depth") + " AS " + e.quote(DepthColumn) + ",")
+	builder.WriteString(tree + "." + e.quote("__path") + " AS " + e.quote(PathColumn))
+	builder.WriteString(" FROM " + e.tableNameQuoted + " JOIN " + tree + " ON " + e.qualify(e.primaryKeyField) + "=" + node)
+	if !withRoot {
+		builder.WriteString(" WHERE " + tree + "." + e.quote("__depth") + ">0")
+	}
+	if direction == treeUp {
+		builder.WriteString(" ORDER BY " + tree + "." + e.quote("__depth"))
+	}
+	return bindPlaceholders(e.dialect, builder.String()), args
+}
+
+// Returns the expression converting the value of the expression to text
+func (e Editor) castText(expr string) string {
+	if e.dialect == MYSQL {
+		return fmt.Sprintf("CAST(%s AS CHAR(4096))", expr)
+	}
+	return fmt.Sprintf("CAST(%s AS TEXT)", expr)
+}
+
+// Returns the expression of the position of the text within the text of the expression, 0 if
+// it is not found
+func (e Editor) position(expr string, text string) string {
+	if e.dialect == POSTGRESQL {
+		return fmt.Sprintf("strpos(%s,%s)", expr, text)
+	}
+	return fmt.Sprintf("instr(%s,%s)", expr, text)
+}
+
+// Returns the expression concatenating the text expressions
+func (e Editor) concat(exprs ...string) string {
+	if e.dialect == MYSQL {
+		return "CONCAT(" + strings.Join(exprs, ",") + ")"
+	}
+	return strings.Join(exprs, "||")
+}
+
+// Orders the rows depth first, comparing their paths key by key rather than by collation
+func sortDepthFirst(rows []DbRow) {
+	paths := make(map[string][]string, len(rows))
+	for _, row := range rows {
+		path, _ := asString(row[PathColumn])
+		paths[path] = strings.Split(path, pathSeparator)
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, _ := asString(rows[i][PathColumn])
+		b, _ := asString(rows[j][PathColumn])
+		return comparePaths(paths[a], paths[b]) < 0
+	})
+}
+
+func comparePaths(a []string, b []string) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if c := compareKeys(a[i], b[i]); c != 0 {
+			return c
+		}
+	}
+	return len(a) - len(b)
+}
+
+func compareKeys(a string, b string) int {
+	x, errA := strconv.ParseInt(a, 10, 64)
+	y, errB := strconv.ParseInt(b, 10, 64)
+	switch {
+	case errA == nil && errB == nil && x < y:
+		return -1
+	case errA == nil && errB == nil && x > y:
+		return 1
+	case errA == nil && errB == nil:
+		return 0
+	}
+	return strings.Compare(a, b)
+}
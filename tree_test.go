@@ -0,0 +1,114 @@
+package crudiator_test
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/SharkFourSix/crudiator"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescendants(t *testing.T) {
+	db, fdb := newFakeDb(t)
+	editor := newCategoryEditor(crudiator.POSTGRESQL)
+
+	fdb.queueRows([]string{"id", "name", "parent_id", "depth", "path"},
+		[]driver.Value{int64(4), "Fiction", int64(1), int64(1), []byte("1/4")},
+		[]driver.Value{int64(9), "Crime", int64(4), int64(2), []byte("1/4/9")},
+	)
+	rows, err := editor.Descendants(crudiator.MapBackedDataForm{"id": 1}, db, 2)
+	require.NoError(t, err)
+	require.Equal(t, `WITH RECURSIVE "__tree"("__node","__parent","__depth","__path") AS (`+
+		`SELECT "id","parent_id",0,CAST("id" AS TEXT) FROM "categories" WHERE "id"=$1 AND "deleted_at" IS NULL`+
+		` UNION ALL SELECT "categories"."id","categories"."parent_id","__tree"."__depth"+1,"__tree"."__path"||'/'||CAST("categories"."id" AS TEXT)`+
+		` FROM "categories" JOIN "__tree" ON "categories"."parent_id"="__tree"."__node" WHERE "deleted_at" IS NULL AND "__tree"."__depth"<$2`+
+		` AND strpos('/'||"__tree"."__path"||'/','/'||CAST("categories"."id" AS TEXT)||'/')=0)`+
+		` SELECT "categories"."id","categories"."name","categories"."parent_id","__tree"."__depth" AS "depth","__tree"."__path" AS "path"`+
+		` FROM "categories" JOIN "__tree" ON "categories"."id"="__tree"."__node" WHERE "__tree"."__depth">0`,
+		fdb.last().Query)
+	require.Equal(t, []any{1, 2}, fdb.last().Args)
+	require.Len(t, rows, 2)
+	require.Equal(t, "1/4/9", rows[1].Get("path"))
+	require.Equal(t, int64(2), rows[1].Get("depth"))
+}
+
+func TestAncestors(t *testing.T) {
+	db, fdb := newFakeDb(t)
+	editor := newCategoryEditor(crudiator.MYSQL)
+
+	fdb.queueRows([]string{"id", "name", "parent_id", "depth", "path"})
+	_, err := editor.Ancestors(crudiator.MapBackedDataForm{"id": 9}, db)
+	require.NoError(t, err)
+	require.Equal(t, "WITH RECURSIVE `__tree`(`__node`,`__parent`,`__depth`,`__path`) AS ("+
+		"SELECT `id`,`parent_id`,0,CAST(`id` AS CHAR(4096)) FROM `categories` WHERE `id`=? AND `deleted_at` IS NULL"+
+		" UNION ALL SELECT `categories`.`id`,`categories`.`parent_id`,`__tree`.`__depth`+1,CONCAT(`__tree`.`__path`,'/',CAST(`categories`.`id` AS CHAR(4096)))"+
+		" FROM `categories` JOIN `__tree` ON `categories`.`id`=`__tree`.`__parent` WHERE `deleted_at` IS NULL"+
+		" AND instr(CONCAT('/',`__tree`.`__path`,'/'),CONCAT('/',CAST(`categories`.`id` AS CHAR(4096)),'/'))=0)"+
+		" SELECT `categories`.`id`,`categories`.`name`,`categories`.`parent_id`,`__tree`.`__depth` AS `depth`,`__tree`.`__path` AS `path`"+
+		" FROM `categories` JOIN `__tree` ON `categories`.`id`=`__tree`.`__node` WHERE `__tree`.`__depth`>0 ORDER BY `__tree`.`__depth`",
+		fdb.last().Query)
+}
+
+func TestSubtreeWithPolicy(t *testing.T) {
+	db, fdb := newFakeDb(t)
+	editor := crudiator.MustNewEditor(
+		"org_units",
+		crudiator.SQLITE,
+		crudiator.NewField("id", crudiator.IsPrimaryKey, crudiator.IncludeOnRead),
+		crudiator.NewField("parent_id", crudiator.IncludeAlways),
+		crudiator.NewField("company_id", crudiator.IsSelectionFilter),
+	).Hierarchy("parent_id").AddPolicy(crudiator.PolicyFunc(
+		func(ctx context.Context, op crudiator.Operation, form crudiator.DataForm) ([]crudiator.Predicate, error) {
+			return []crudiator.Predicate{crudiator.Where("`archived`=?", false)}, nil
+		},
+	)).Build()
+
+	fdb.queueRows([]string{"id", "parent_id", "depth", "path"})
+	_, err := editor.Subtree(crudiator.MapBackedDataForm{"id": 1, "company_id": 7}, db)
+	require.NoError(t, err)
+	require.Contains(t, fdb.last().Query, "WHERE `id`=? AND `company_id`=? AND (`archived`=?) UNION ALL")
+	require.Contains(t, fdb.last().Query, "WHERE `company_id`=? AND (`archived`=?)"+
+		" AND instr('/'||`__tree`.`__path`||'/','/'||CAST(`org_units`.`id` AS TEXT)||'/')=0) SELECT")
+	require.NotContains(t, fdb.last().Query, "`__depth`>0")
+	require.Equal(t, []any{1, 7, false, 7, false}, fdb.last().Args)
+
+	_, err = newStudentEditor(crudiator.SQLITE).Build().Subtree(crudiator.MapBackedDataForm{"id": 1}, db)
+	require.ErrorIs(t, err, crudiator.ErrHierarchyNotConfigured)
+}
+
+func TestSubtreeDepthFirst(t *testing.T) {
+	db, fdb := newFakeDb(t)
+	editor := newCategoryEditor(crudiator.POSTGRESQL)
+
+	// as sorted by a database comparing the paths as text
+	fdb.queueRows([]string{"id", "name", "parent_id", "depth", "path"},
+		[]driver.Value{int64(1), "Books", nil, int64(0), []byte("1")},
+		[]driver.Value{int64(10), "Poetry", int64(1), int64(1), []byte("1/10")},
+		[]driver.Value{int64(2), "Fiction", int64(1), int64(1), []byte("1/2")},
+		[]driver.Value{int64(11), "Sonnets", int64(10), int64(2), []byte("1/10/11")},
+		[]driver.Value{int64(3), "Crime", int64(2), int64(2), []byte("1/2/3")},
+	)
+	rows, err := editor.Subtree(crudiator.MapBackedDataForm{"id": 1}, db)
+	require.NoError(t, err)
+	var paths []any
+	for _, row := range rows {
+		paths = append(paths, row.Get("path"))
+	}
+	require.Equal(t, []any{"1", "1/2", "1/2/3", "1/10", "1/10/11"}, paths)
+
+	// '-' sorts before '/' in most collations
+	fdb.queueRows([]string{"id", "name", "parent_id", "depth", "path"},
+		[]driver.Value{"a", "Books", nil, int64(0), "a"},
+		[]driver.Value{"b", "Fiction", "a", int64(1), "a/b"},
+		[]driver.Value{"b-c", "Poetry", "a", int64(1), "a/b-c"},
+		[]driver.Value{"d", "Crime", "b", int64(2), "a/b/d"},
+	)
+	rows, err = editor.Subtree(crudiator.MapBackedDataForm{"id": "a"}, db)
+	require.NoError(t, err)
+	paths = nil
+	for _, row := range rows {
+		paths = append(paths, row.Get("path"))
+	}
+	require.Equal(t, []any{"a", "a/b", "a/b/d", "a/b-c"}, paths)
+}
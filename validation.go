@@ -0,0 +1,173 @@
+package crudiator
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// Validator checks the value of a field, nil if the form does not carry it, and returns an error
+// describing why the value is invalid
+type Validator func(value any) error
+
+// ValidationErrors is returned by Create and Update when one or more field values fail
+// validation. It maps field names to the messages of the failed rules and can readily be
+// serialized as JSON.
+type ValidationErrors map[string][]string
+
+func (v ValidationErrors) Error() string {
+	fields := make([]string, 0, len(v))
+	for f := range v {
+		fields = append(fields, f)
+	}
+	sort.Strings(fields)
+
+	var builder strings.Builder
+	builder.WriteString("validation failed: ")
+	for i, f := range fields {
+		if i > 0 {
+			builder.WriteString("; ")
+		}
+		builder.WriteString(f)
+		builder.WriteString(": ")
+		builder.WriteString(strings.Join(v[f], ", "))
+	}
+	return builder.String()
+}
+
+func (v ValidationErrors) add(field, message string) {
+	v[field] = append(v[field], message)
+}
+
+// Reports whether the value is missing, which all rules but Required accept
+func isBlank(value any) bool {
+	if value == nil {
+		return true
+	}
+	if s, ok := value.(string); ok {
+		return strings.TrimSpace(s) == ""
+	}
+	return false
+}
+
+var (
+	// The field must be present in the form and not be blank
+	Required FieldOption = ValidateWith(func(value any) error {
+		if isBlank(value) {
+			return errors.New("is required")
+		}
+		return nil
+	})
+
+	// Adds a custom validation rule to the field
+	ValidateWith = func(v Validator) FieldOption {
+		return func(f *Field) { f.Validators = append(f.Validators, v) }
+	}
+
+	// The value, as text, must be at least n characters long
+	MinLen = func(n int) FieldOption {
+		return ValidateWith(func(value any) error {
+			if isBlank(value) {
+				return nil
+			}
+			s, err := asString(value)
+			if err != nil || utf8.RuneCountInString(s) < n {
+				return errors.Errorf("must be at least %d characters long", n)
+			}
+			return nil
+		})
+	}
+
+	// The value, as text, must be at most n characters long
+	MaxLen = func(n int) FieldOption {
+		return ValidateWith(func(value any) error {
+			if isBlank(value) {
+				return nil
+			}
+			s, err := asString(value)
+			if err != nil || utf8.RuneCountInString(s) > n {
+				return errors.Errorf("must be at most %d characters long", n)
+			}
+			return nil
+		})
+	}
+
+	// The value must be a number between low and high, inclusive
+	Range = func(low, high float64) FieldOption {
+		return ValidateWith(func(value any) error {
+			if isBlank(value) {
+				return nil
+			}
+			n, err := asFloat64(value)
+			if err != nil {
+				return errors.New("must be a number")
+			}
+			if n < low || n > high {
+				return errors.Errorf("must be between %v and %v", low, high)
+			}
+			return nil
+		})
+	}
+
+	// The value must be one of the given values. Values are compared by their textual
+	// representation, so that "1" from a form matches 1.
+	OneOf = func(values ...any) FieldOption {
+		allowed := make([]string, len(values))
+		for i, v := range values {
+			allowed[i] = fmt.Sprint(v)
+		}
+		return ValidateWith(func(value any) error {
+			if isBlank(value) {
+				return nil
+			}
+			s := fmt.Sprint(value)
+			for _, a := range allowed {
+				if s == a {
+					return nil
+				}
+			}
+			return errors.Errorf("must be one of %s", strings.Join(allowed, ", "))
+		})
+	}
+
+	// The value, as text, must match the regular expression
+	Matches = func(re *regexp.Regexp) FieldOption {
+		return ValidateWith(func(value any) error {
+			if isBlank(value) {
+				return nil
+			}
+			s, err := asString(value)
+			if err != nil || !re.MatchString(s) {
+				return errors.New("has an invalid format")
+			}
+			return nil
+		})
+	}
+)
+
+// Runs the validation rules of the fields written by the operation
+func (e Editor) validate(op Operation, form DataForm) error {
+	failures := ValidationErrors{}
+	for _, f := range e.fields {
+		if len(f.Validators) == 0 || f.managedOn(op) {
+			continue
+		}
+		if (op == OpCreate && !f.Create) || (op == OpUpdate && !f.Update) {
+			continue
+		}
+		value := form.Get(f.Name)
+		for _, v := range f.Validators {
+			if err := v(value); err != nil {
+				failures.add(f.Name, err.Error())
+			}
+		}
+	}
+	if len(failures) > 0 {
+		return failures
+	}
+	return nil
+}
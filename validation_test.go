@@ -0,0 +1,48 @@
+package crudiator_test
+
+import (
+	"encoding/json"
+	"regexp"
+	"testing"
+
+	"github.com/SharkFourSix/crudiator"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidation(t *testing.T) {
+	db, fdb := newFakeDb(t)
+	editor := crudiator.MustNewEditor(
+		"students",
+		crudiator.POSTGRESQL,
+		crudiator.NewField("id", crudiator.IsPrimaryKey, crudiator.IncludeOnRead),
+		crudiator.NewField("name", crudiator.IncludeAlways, crudiator.Required, crudiator.MaxLen(5)),
+		crudiator.NewField("age", crudiator.IncludeAlways, crudiator.Range(5, 20)),
+		crudiator.NewField("grade", crudiator.IncludeAlways, crudiator.OneOf("A", "B", "C")),
+		crudiator.NewField("code", crudiator.IncludeOnCreate, crudiator.Required, crudiator.Matches(regexp.MustCompile(`^[A-Z]{3}$`))),
+	).Build()
+
+	form := crudiator.MapBackedDataForm{"name": "Johnathan", "age": "42", "grade": "F", "code": "abc"}
+	_, err := editor.Create(form, db)
+	var failures crudiator.ValidationErrors
+	require.ErrorAs(t, err, &failures)
+	require.Equal(t, crudiator.ValidationErrors{
+		"name":  {"must be at most 5 characters long"},
+		"age":   {"must be between 5 and 20"},
+		"grade": {"must be one of A, B, C"},
+		"code":  {"has an invalid format"},
+	}, failures)
+	require.Empty(t, fdb.all())
+
+	data, err := json.Marshal(failures)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"name":["must be at most 5 characters long"],"age":["must be between 5 and 20"],"grade":["must be one of A, B, C"],"code":["has an invalid format"]}`, string(data))
+
+	// code is not updatable, so its rules do not apply on update, and blank optional values pass
+	_, err = editor.Update(crudiator.MapBackedDataForm{"id": 1, "name": "", "age": ""}, db)
+	require.ErrorAs(t, err, &failures)
+	require.Equal(t, crudiator.ValidationErrors{"name": {"is required"}}, failures)
+
+	_, err = editor.Create(crudiator.MapBackedDataForm{"name": "Jane", "age": 12, "grade": "B", "code": "ABC"}, db)
+	require.NoError(t, err)
+	require.Len(t, fdb.all(), 1)
+}
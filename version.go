@@ -0,0 +1,85 @@
+package crudiator
+
+import (
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+// Returned by Update and Delete when the version carried by the form no longer matches the
+// row, meaning that the row has been modified or deleted since it was read
+var ErrStaleVersion = errors.New("stale version")
+
+// Marks the field as the version column used for optimistic locking. Update and Delete return
+// ErrStaleVersion when the version of the row differs from the form's.
+var IsVersion FieldOption = func(f *Field) { f.Version = true }
+
+// Returns the SET clause assignment incrementing the version, if any
+func (e Editor) versionIncrement() string {
+	v := e.quote(e.versionField)
+	return v + "=" + v + "+1"
+}
+
+// Returns the version carried by the form, which is bound after the selection values of
+// Update and Delete. Returns nothing if the editor has no version field.
+func (e Editor) getVersionValues(form DataForm) ([]any, error) {
+	if e.versionField == "" {
+		return nil, nil
+	}
+	value := form.Get(e.versionField)
+	if isBlank(value) {
+		return nil, ValidationErrors{e.versionField: {"is required"}}
+	}
+	value, err := e.coerceFieldValue(e.versionField, value)
+	if err != nil {
+		return nil, err
+	}
+	return []any{value}, nil
+}
+
+// Returns ErrStaleVersion if the statement did not affect any row of a versioned table
+func (e Editor) checkAffected(res sql.Result) error {
+	if e.versionField == "" {
+		return nil
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrStaleVersion
+	}
+	return nil
+}
+
+// Scans the row returned by a RETURNING statement, or returns ErrStaleVersion if a versioned
+// table did not return any
+func (e Editor) scanReturnedRow(rows *sql.Rows) (DbRow, error) {
+	row, _, err := e.scanAffectedRow(rows)
+	return row, err
+}
+
+// Same as 'scanReturnedRow()', also returning whether a row was returned
+func (e Editor) scanAffectedRow(rows *sql.Rows) (DbRow, bool, error) {
+	defer rows.Close()
+	scanned, err := e.scanRows(rows)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(scanned) == 0 {
+		if e.versionField != "" {
+			return nil, false, ErrStaleVersion
+		}
+		return DbRow{}, false, nil
+	}
+	return scanned[0], true, nil
+}
+
+// Returns whether the statement affected any row
+func rowsAffected(res sql.Result) (bool, error) {
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
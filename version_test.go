@@ -0,0 +1,70 @@
+package crudiator_test
+
+import (
+	"database/sql/driver"
+	"testing"
+
+	"github.com/SharkFourSix/crudiator"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	versionField     = crudiator.NewField("version", crudiator.IncludeOnRead, crudiator.IsVersion, crudiator.OfType(crudiator.IntField))
+	softDeletedField = crudiator.NewField("deleted_at", crudiator.SoftDeleteAs(crudiator.BoolField))
+)
+
+func TestOptimisticLockingReturning(t *testing.T) {
+	db, fdb := newFakeDb(t)
+	editor := newStudentEditor(crudiator.POSTGRESQL, versionField, schoolFilter, softDeletedField).SoftDelete(true).Build()
+
+	fdb.queueRows([]string{"id", "version"}, []driver.Value{int64(1), int64(1)})
+	_, err := editor.Create(crudiator.MapBackedDataForm{"name": "Jane", "version": 7}, db)
+	require.NoError(t, err)
+	require.Contains(t, fdb.last().Query, `("name","version") VALUES ($1,1)`)
+	require.Equal(t, []any{"Jane"}, fdb.last().Args)
+
+	fdb.queueRows([]string{"id", "version"}, []driver.Value{int64(1), int64(2)})
+	form := crudiator.MapBackedDataForm{"id": 1, "name": "Jane", "school_id": 3, "version": "1"}
+	row, err := editor.Update(form, db)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), row["version"])
+	require.Contains(t, fdb.last().Query, `SET "name"=$1,"version"="version"+1 WHERE "id"=$2 AND ("school_id"=$3) AND "version"=$4 RETURNING`)
+	require.Equal(t, []any{"Jane", 1, 3, int64(1)}, fdb.last().Args)
+
+	fdb.queueRows([]string{"id", "version"})
+	_, err = editor.Update(form, db)
+	require.ErrorIs(t, err, crudiator.ErrStaleVersion)
+
+	fdb.queueRows([]string{"id", "version"})
+	_, err = editor.Delete(form, db)
+	require.ErrorIs(t, err, crudiator.ErrStaleVersion)
+	require.Contains(t, fdb.last().Query, `SET "deleted_at"=$1,"version"="version"+1 WHERE "id"=$2 AND ("school_id"=$3) AND "version"=$4 RETURNING`)
+
+	delete(form, "version")
+	_, err = editor.Update(form, db)
+	var validationErrs crudiator.ValidationErrors
+	require.ErrorAs(t, err, &validationErrs)
+	require.Contains(t, validationErrs, "version")
+}
+
+func TestOptimisticLockingExec(t *testing.T) {
+	db, fdb := newFakeDb(t)
+	editor := newStudentEditor(crudiator.MYSQL, versionField, schoolFilter, softDeletedField).Strict(true).Build()
+
+	form := crudiator.MapBackedDataForm{"id": 1, "name": "Jane", "school_id": 3, "version": 1}
+	fdb.queue(fakeResult{RowsAffected: 1})
+	fdb.queueRows([]string{"id", "version"}, []driver.Value{int64(1), int64(2)})
+	row, err := editor.Update(form, db)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), row["version"])
+	require.Equal(t, "UPDATE `students` SET `name`=?,`version`=`version`+1 WHERE `id`=? AND (`school_id`=?) AND `version`=?", fdb.all()[0].Query)
+
+	fdb.queue(fakeResult{RowsAffected: 0})
+	_, err = editor.Update(form, db)
+	require.ErrorIs(t, err, crudiator.ErrStaleVersion)
+
+	fdb.queue(fakeResult{RowsAffected: 0})
+	_, err = editor.Delete(crudiator.MapBackedDataForm{"id": 1, "school_id": 3, "version": 1}, db)
+	require.ErrorIs(t, err, crudiator.ErrStaleVersion)
+	require.Equal(t, "DELETE FROM `students` WHERE `id`=? AND (`school_id`=?) AND `version`=?", fdb.last().Query)
+}
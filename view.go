@@ -0,0 +1,72 @@
+package crudiator
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+// MustNewViewEditor creates a read-only editor reading from a view. See 'MustNewEditor()'
+func MustNewViewEditor(view string, dialect SQLDialect, fields ...Field) *Editor {
+	return MustNewEditor(view, dialect, fields...).ReadOnly()
+}
+
+// MustNewQueryEditor creates a read-only editor reading from the rows of a SELECT statement without
+// parameters, used as a subquery named alias. See 'MustNewEditor()'
+func MustNewQueryEditor(alias string, query string, dialect SQLDialect, fields ...Field) *Editor {
+	query = strings.TrimRight(strings.TrimSpace(query), "; \t\n")
+	if query == "" {
+		panic("query cannot be empty")
+	}
+	if err := checkSubquery(dialect, query); err != nil {
+		panic(err)
+	}
+	e := MustNewEditor(alias, dialect, fields...).ReadOnly()
+	e.query = query
+	e.tableNameQuoted = fmt.Sprintf("(%s) AS %c%s%c", query, e.quoteRune, alias, e.quoteRune)
+	return e
+}
+
+func (e *Editor) checkQuerySource() *Editor {
+	if e.query == "" {
+		return e
+	}
+	for _, op := range []Operation{OpCreate, OpUpdate, OpDelete} {
+		if e.Allows(op) {
+			panic(errors.Errorf("%s cannot be enabled on the query editor '%s'", op, e.tableName))
+		}
+	}
+	return e
+}
+
+// Returns an error if the statement has parameters, whose arguments could not be bound, or ends
+// within a comment, which would hide the end of the subquery
+func checkSubquery(dialect SQLDialect, query string) error {
+	if countPlaceholders(dialect, query) > 0 {
+		return errors.New("query cannot have parameters")
+	}
+	for i := 0; i < len(query); i++ {
+		if end := skipQuoted(dialect, query, i); end > i {
+			comment := query[i:end]
+			if (strings.HasPrefix(comment, "--") || strings.HasPrefix(comment, "#")) && !strings.HasSuffix(comment, "\n") {
+				return errors.New("query cannot end with a line comment")
+			}
+			if strings.HasPrefix(comment, "/*") && (len(comment) < 4 || !strings.HasSuffix(comment, "*/")) {
+				return errors.New("query cannot end within a comment")
+			}
+			i = end - 1
+			continue
+		}
+		if dialect == POSTGRESQL && query[i] == '$' && i+1 < len(query) && unicode.IsDigit(rune(query[i+1])) &&
+			(i == 0 || !isIdentifierByte(query[i-1])) {
+			return errors.New("query cannot have parameters")
+		}
+	}
+	return nil
+}
+
+func isIdentifierByte(c byte) bool {
+	return c == '_' || c == '$' || unicode.IsLetter(rune(c)) || unicode.IsDigit(rune(c)) || c >= 0x80
+}
@@ -0,0 +1,96 @@
+package crudiator_test
+
+import (
+	"database/sql/driver"
+	"testing"
+
+	"github.com/SharkFourSix/crudiator"
+	"github.com/stretchr/testify/require"
+)
+
+const studentSchools = `SELECT s.id, s.name, c.name AS school FROM students s JOIN schools c ON c.id = s.school_id`
+
+var studentSchoolFields = []crudiator.Field{
+	crudiator.NewField("id", crudiator.IsPrimaryKey, crudiator.IncludeOnRead),
+	crudiator.NewField("name", crudiator.IncludeOnRead, crudiator.IsSelectionFilter),
+	crudiator.NewField("school", crudiator.IncludeOnRead),
+}
+
+func TestQueryEditor(t *testing.T) {
+	db, fdb := newFakeDb(t)
+	editor := crudiator.MustNewQueryEditor("student_schools", studentSchools+";", crudiator.POSTGRESQL, studentSchoolFields...).
+		MustPaginate(crudiator.KEYSET, "id").Build()
+	source := `(` + studentSchools + `) AS "student_schools"`
+
+	fdb.queueRows([]string{"id", "name", "school"}, []driver.Value{int64(4), "Jane", "Chichiri"})
+	fdb.queueRows([]string{"count"}, []driver.Value{int64(1)})
+	page, err := editor.ReadPage(crudiator.MapBackedDataForm{"name": "Jane"}, db, crudiator.NewKeysetPaging(0, 10), true)
+	require.NoError(t, err)
+	require.Len(t, page.Rows, 1)
+	require.Equal(t, "Chichiri", page.Rows[0].Get("school"))
+
+	queries := fdb.all()
+	require.Equal(t, `SELECT "id","name","school" FROM `+source+` WHERE ("name"=$1) AND ("id">$2) ORDER BY "id" ASC LIMIT $3`, queries[0].Query)
+	require.Equal(t, `SELECT COUNT(*) FROM `+source+` WHERE ("name"=$1)`, queries[1].Query)
+
+	fdb.queueRows([]string{"id", "name", "school"})
+	_, err = editor.SingleRead(crudiator.MapBackedDataForm{"id": 4, "name": "Jane"}, db)
+	require.NoError(t, err)
+	require.Equal(t, `SELECT "id","name","school" FROM `+source+` WHERE ("id"=$1) AND ("name"=$2)`, fdb.last().Query)
+
+	_, err = editor.Create(crudiator.MapBackedDataForm{"name": "Jane"}, db)
+	require.ErrorIs(t, err, crudiator.ErrOperationNotAllowed)
+}
+
+func TestQueryEditorWrites(t *testing.T) {
+	require.Panics(t, func() {
+		crudiator.MustNewQueryEditor("student_schools", studentSchools, crudiator.POSTGRESQL, studentSchoolFields...).
+			AllowOperations(crudiator.OpRead, crudiator.OpUpdate).Build()
+	})
+}
+
+func TestQuerySource(t *testing.T) {
+	id := crudiator.NewField("id", crudiator.IsPrimaryKey, crudiator.IncludeOnRead)
+	for _, c := range []struct {
+		dialect crudiator.SQLDialect
+		query   string
+		err     string
+	}{
+		{crudiator.POSTGRESQL, `SELECT id FROM students WHERE school_id = $1`, "query cannot have parameters"},
+		{crudiator.MYSQL, `SELECT id FROM students WHERE school_id = ?`, "query cannot have parameters"},
+		{crudiator.POSTGRESQL, "SELECT id FROM students -- all of them", "query cannot end with a line comment"},
+		{crudiator.MYSQL, "SELECT id FROM students # all of them\n;\n", "query cannot end with a line comment"},
+		{crudiator.SQLITE, "SELECT id FROM students /* all of them", "query cannot end within a comment"},
+	} {
+		require.PanicsWithError(t, c.err, func() {
+			crudiator.MustNewQueryEditor("students", c.query, c.dialect, id)
+		}, c.query)
+	}
+
+	// question marks and dollar signs which are not parameters, and comments followed by more text
+	for _, query := range []string{
+		`SELECT id FROM students WHERE name <> 'who?' AND note <> '$1' AND tags ?| ARRAY['a']`,
+		"SELECT id, price$1 FROM students -- all of them\nWHERE id > 0 /* positive */",
+	} {
+		editor := crudiator.MustNewQueryEditor("students", query, crudiator.POSTGRESQL, id).Build()
+		require.NotNil(t, editor)
+	}
+}
+
+func TestViewEditor(t *testing.T) {
+	db, fdb := newFakeDb(t)
+	editor := crudiator.MustNewViewEditor(
+		"student_schools",
+		crudiator.MYSQL,
+		crudiator.NewField("id", crudiator.IsPrimaryKey, crudiator.IncludeOnRead),
+		crudiator.NewField("school", crudiator.IncludeOnRead),
+	).Build()
+
+	fdb.queueRows([]string{"id", "school"})
+	_, err := editor.Read(crudiator.MapBackedDataForm{}, db)
+	require.NoError(t, err)
+	require.Equal(t, "SELECT `id`,`school` FROM `student_schools`", fdb.last().Query)
+
+	_, err = editor.Delete(crudiator.MapBackedDataForm{"id": 1}, db)
+	require.ErrorIs(t, err, crudiator.ErrOperationNotAllowed)
+}